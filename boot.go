@@ -0,0 +1,142 @@
+package dimple
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/thoas/go-funk"
+)
+
+type bootResult struct {
+	id  string
+	err error
+}
+
+func (c *DefaultContainer) BootParallel(ctx context.Context, workers int) error {
+	if c.parent != nil {
+		return c.parent.BootParallel(ctx, workers)
+	}
+
+	c.eager.Store(true)
+	c.booting.Add(1)
+	defer c.booting.Add(-1)
+
+	start := time.Now()
+	booted, err := c.bootParallel(ctx, workers)
+
+	c.notify(func(l Listener) {
+		l.AfterBoot(booted, time.Since(start), err)
+	})
+
+	return err
+}
+
+// bootParallel boots the services and returns the number of services booted successfully
+func (c *DefaultContainer) bootParallel(ctx context.Context, workers int) (int, error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	if err := c.rewire(); err != nil {
+		return 0, err
+	}
+
+	ids, edges, err := c.getDependencyGraph()
+	if err != nil {
+		return 0, err
+	}
+
+	if ids, err = sortTopological(ids, edges); err != nil {
+		return 0, err
+	}
+
+	// a service becomes ready to be booted once all of its dependencies have been booted
+	pending := make(map[string]int, len(ids))
+	dependents := make(map[string][]string, len(ids))
+	ready := make([]string, 0, len(ids))
+	for _, id := range ids {
+		for _, dep := range edges[id] {
+			if dep == id || funk.ContainsString(dependents[dep], id) {
+				continue
+			}
+
+			pending[id]++
+			dependents[dep] = append(dependents[dep], id)
+		}
+
+		if pending[id] == 0 {
+			ready = append(ready, id)
+		}
+	}
+
+	bootable := c.getAllServiceIDs()
+	scoped := c.withContext(ctx)
+	jobs := make(chan string)
+	results := make(chan bootResult)
+	defer close(jobs)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for id := range jobs {
+				var err error
+				if funk.ContainsString(bootable, id) {
+					_, err = scoped.getValue(id)
+				}
+
+				results <- bootResult{id: id, err: err}
+			}
+		}()
+	}
+
+	errs := make([]error, 0)
+	booted := 0
+	done := ctx.Done()
+	running := 0
+	for len(ready) > 0 || running > 0 {
+		if done != nil && ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			ready = nil
+			done = nil
+
+			continue
+		}
+
+		var next string
+		var queue chan string
+		if len(ready) > 0 {
+			next = ready[0]
+			queue = jobs
+		}
+
+		select {
+		case queue <- next:
+			ready = ready[1:]
+			running++
+		case res := <-results:
+			running--
+			if res.err != nil {
+				// services depending on a failed one will not be booted
+				errs = append(errs, res.err)
+				continue
+			}
+
+			if funk.ContainsString(bootable, res.id) {
+				booted++
+			}
+
+			for _, dependent := range dependents[res.id] {
+				if pending[dependent]--; pending[dependent] == 0 {
+					ready = append(ready, dependent)
+				}
+			}
+		case <-done:
+			// wait for the services in progress but do not boot any further
+			errs = append(errs, ctx.Err())
+			ready = nil
+			done = nil
+		}
+	}
+
+	return booted, joinErrors(errs)
+}
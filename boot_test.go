@@ -0,0 +1,172 @@
+// nolint
+package dimple
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thoas/go-funk"
+)
+
+// barrier blocks until the given number of parties have arrived or the timeout elapsed
+type barrier struct {
+	wg sync.WaitGroup
+}
+
+func newBarrier(parties int) *barrier {
+	b := &barrier{}
+	b.wg.Add(parties)
+
+	return b
+}
+
+func (b *barrier) await() bool {
+	b.wg.Done()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(time.Second):
+		return false
+	}
+}
+
+func TestContainer_BootParallel(t *testing.T) {
+	b := newBarrier(3)
+	r := &bootRecorder{}
+
+	client := func(id string) Definition {
+		return Service(id, WithErrorFn(func() (any, error) {
+			if !b.await() {
+				return nil, errors.New("clients have not been booted concurrently")
+			}
+
+			r.record(id)
+			return &randomService{Name: id}, nil
+		}))
+	}
+
+	ctn := Builder(
+		Service("service.a", WithFn(func() any {
+			r.record("service.a")
+			return &callableService{}
+		})).WithCall("SetDep", "client.b"),
+		client("client.a"),
+		client("client.b"),
+		client("client.c"),
+	).
+		MustBuild(context.TODO())
+
+	assert.NoError(t, ctn.BootParallel(context.TODO(), 3))
+	assert.Len(t, r.booted, 4)
+	assert.Greater(t, funk.IndexOfString(r.booted, "service.a"), funk.IndexOfString(r.booted, "client.b"))
+	assert.Same(t, ctn.MustGet("client.b"), ctn.MustGet("service.a").(*callableService).dep)
+}
+
+func TestContainer_BootParallelOnce(t *testing.T) {
+	var calls int32
+
+	ctn := Builder(
+		Service("service.slow", WithFn(func() any {
+			atomic.AddInt32(&calls, 1)
+			time.Sleep(10 * time.Millisecond)
+
+			return &randomService{}
+		})),
+	).
+		MustBuild(context.TODO())
+
+	// dependencies on the slow service are not known before the first instantiation
+	for _, id := range []string{"service.a", "service.b", "service.c", "service.d"} {
+		ctn.Override(Service(id, WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return ctx.Container().Get("service.slow")
+		})))
+	}
+
+	assert.NoError(t, ctn.BootParallel(context.TODO(), 4))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Same(t, ctn.MustGet("service.slow"), ctn.MustGet("service.a"))
+}
+
+func TestContainer_BootParallelErrors(t *testing.T) {
+	r := &bootRecorder{}
+
+	ctn := Builder(
+		Service("service.a", WithErrorFn(func() (any, error) {
+			return nil, errors.New("error a")
+		})),
+		Service("service.b", WithErrorFn(func() (any, error) {
+			return nil, errors.New("error b")
+		})),
+		Service("service.c", WithFn(func() any {
+			r.record("service.c")
+			return &callableService{}
+		})).WithCall("SetDep", "service.a"),
+		Service("service.d", WithFn(func() any {
+			r.record("service.d")
+			return &randomService{}
+		})),
+	).
+		MustBuild(context.TODO())
+
+	err := ctn.BootParallel(context.TODO(), 2)
+	assert.ErrorIs(t, err, ErrServiceFactoryFailed)
+	assert.ErrorContains(t, err, "error a")
+	assert.ErrorContains(t, err, "error b")
+	assert.Equal(t, []string{"service.d"}, r.booted)
+}
+
+func TestContainer_BootParallelCancelled(t *testing.T) {
+	r := &bootRecorder{}
+	ctn := Builder(
+		Service("service.a", WithFn(func() any {
+			r.record("service.a")
+			return &callableService{}
+		})).WithCall("SetDep", "service.b"),
+		Service("service.b", WithFn(func() any {
+			r.record("service.b")
+			return &randomService{Name: "B"}
+		})),
+		Decorator("decorator.b", "service.b", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			r.record("decorator.b")
+			return ctx.Decorated(), nil
+		})),
+		Param("param.a", "A"),
+	).
+		MustBuild(context.TODO())
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	assert.ErrorIs(t, ctn.BootParallel(ctx, 2), context.Canceled)
+	assert.Empty(t, r.booted)
+}
+
+func TestContainer_BootParallelCircular(t *testing.T) {
+	b := newBarrier(2)
+
+	ctn := Builder(
+		Service("service.a", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			b.await()
+			return ctx.Container().Get("service.b")
+		})),
+		Service("service.b", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			b.await()
+			return ctx.Container().Get("service.a")
+		})),
+	).
+		MustBuild(context.TODO())
+
+	assert.ErrorIs(t, ctn.BootParallel(context.TODO(), 2), ErrCircularDependency)
+}
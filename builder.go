@@ -2,6 +2,7 @@ package dimple
 
 import (
 	"context"
+	"fmt"
 )
 
 var _ ContainerBuilder = (*DefaultBuilder)(nil)
@@ -13,12 +14,17 @@ func Builder(defs ...Definition) *DefaultBuilder {
 			order:       make([]string, 0),
 			definitions: make(map[string]Definition),
 		},
+		modules: make([]string, 0),
+		owners:  make(map[string]string),
+		sources: make(map[string]uintptr),
+		errs:    make([]error, 0),
 	}
 
-	b.Add(Service("container", WithInstance(b.container)))
+	b.register(builtin("container", b.container), 0, false)
 
+	source := b.caller(2)
 	for _, def := range defs {
-		b.Add(def)
+		b.register(def, source, false)
 	}
 
 	return b
@@ -26,6 +32,39 @@ func Builder(defs ...Definition) *DefaultBuilder {
 
 type DefaultBuilder struct {
 	container *DefaultContainer
+	modules   []string
+	owners    map[string]string
+	installer string
+	namespace string
+	sources   map[string]uintptr
+	errs      []error
+	built     bool
+	noSources bool
+}
+
+// WithSourceCapture enables or disables keeping the file and line where definitions are declared and added to the
+// builder. It is enabled by default. Once disabled the builder discards the sources of all definitions, including
+// those added so far, and no longer records where they have been added. Service(), Param() and Decorator() still
+// capture the program counter of their caller, since they are called before the builder sees the definition.
+func (b *DefaultBuilder) WithSourceCapture(enabled bool) *DefaultBuilder {
+	b.noSources = !enabled
+	if b.noSources {
+		for _, id := range b.container.getOrder() {
+			b.container.set(id, withoutSource(b.container.getDefinition(id)))
+			b.sources[id] = 0
+		}
+	}
+
+	return b
+}
+
+// WithTypeProbing enables instantiating services to find the argument of an Inject* method by its type, if none
+// of the parameters and services instantiated so far matches. It is disabled by default, since it instantiates
+// services which might not be needed at all and their factories might have side effects.
+func (b *DefaultBuilder) WithTypeProbing(enabled bool) *DefaultBuilder {
+	b.container.probeTypes = enabled
+
+	return b
 }
 
 func (b *DefaultBuilder) MustBuild(ctx context.Context) *DefaultContainer {
@@ -39,12 +78,17 @@ func (b *DefaultBuilder) MustBuild(ctx context.Context) *DefaultContainer {
 
 func (b *DefaultBuilder) Build(ctx context.Context) (*DefaultContainer, error) {
 	c := b.container
-	c.ctx = ctx
+	c.ctx = WithContainer(ctx, c)
+
+	b.register(builtin("context", c.ctx), 0, b.built)
+	b.built = true
 
-	b.Add(Service("context", WithInstance(ctx)))
+	if len(b.errs) > 0 {
+		return nil, joinErrors(b.errs)
+	}
 
-	// mandatory boot of decorated services to rewrite the decorated definitions
-	if err := c.boot(c.getAllDecoratorIDs()...); err != nil {
+	// decorated definitions need to be rewired before first use
+	if err := c.rewire(); err != nil {
 		return nil, err
 	}
 
@@ -52,11 +96,50 @@ func (b *DefaultBuilder) Build(ctx context.Context) (*DefaultContainer, error) {
 }
 
 func (b *DefaultBuilder) Add(def Definition) ContainerBuilder {
-	b.container.add(def.Id(), def)
+	b.register(def, b.caller(2), false)
+
+	return b
+}
+
+func (b *DefaultBuilder) Override(def Definition) ContainerBuilder {
+	b.register(def, b.caller(2), true)
 
 	return b
 }
 
+// register adds the definition to the container. Unless override is TRUE the definition will be rejected
+// if its ID has already been registered. The error is reported on Build().
+func (b *DefaultBuilder) register(def Definition, source uintptr, override bool) {
+	if b.namespace != "" {
+		def = withNamespace(def, b.namespace)
+	}
+
+	if b.noSources {
+		def = withoutSource(def)
+	}
+
+	id := def.Id()
+	if b.container.isCompiled() {
+		// the builder writes to the same definitions as the container
+		panic(fmt.Sprintf(`cannot register "%s" since the container has been compiled`, id))
+	}
+
+	if !override && b.Has(id) {
+		b.errs = append(b.errs, fmt.Errorf(`%w: "%s" registered at %s has already been registered at %s`, ErrDefinitionConflict, id, describeSource(source, b.installer), describeSource(b.sources[id], b.owners[id])))
+		return
+	}
+
+	b.container.add(id, def)
+	b.container.notify(func(l Listener) {
+		l.OnDefinitionAdded(def)
+	})
+
+	b.sources[id] = source
+	if b.installer != "" {
+		b.owners[id] = b.installer
+	}
+}
+
 func (b *DefaultBuilder) Get(id string) Definition {
 	return b.container.getDefinition(id)
 }
@@ -64,3 +147,95 @@ func (b *DefaultBuilder) Get(id string) Definition {
 func (b *DefaultBuilder) Has(id string) bool {
 	return b.container.Has(id)
 }
+
+func (b *DefaultBuilder) Install(modules ...Module) error {
+	sorted, err := sortModules(modules, b.modules)
+	if err != nil {
+		return err
+	}
+
+	// check for conflicting definitions upfront, so the builder remains untouched
+	registered := make(map[string]string)
+	for _, m := range sorted {
+		for _, def := range m.Definitions {
+			id := m.id(def)
+			if owner, ok := registered[id]; ok {
+				return b.conflict(m.Name, id, owner)
+			}
+
+			if b.Has(id) {
+				return b.conflict(m.Name, id, b.owners[id])
+			}
+
+			registered[id] = m.Name
+		}
+	}
+
+	source := b.caller(2)
+	for _, m := range sorted {
+		if err = b.install(m, source); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *DefaultBuilder) install(m Module, source uintptr) error {
+	prevInstaller, prevNamespace := b.installer, b.namespace
+	b.installer, b.namespace = m.Name, m.Namespace
+	defer func() {
+		b.installer, b.namespace = prevInstaller, prevNamespace
+	}()
+
+	b.modules = append(b.modules, m.Name)
+	for _, def := range m.Definitions {
+		b.register(def, source, false)
+	}
+
+	if m.Configure == nil {
+		return nil
+	}
+
+	if err := m.Configure(b); err != nil {
+		return fmt.Errorf(`cannot configure module "%s": %w`, m.Name, err)
+	}
+
+	return nil
+}
+
+func (b *DefaultBuilder) conflict(module, id, owner string) error {
+	if owner == "" {
+		return fmt.Errorf(`%w: module "%s" registers "%s" which has already been registered outside of any module`, ErrDefinitionConflict, module, id)
+	}
+
+	return fmt.Errorf(`%w: module "%s" registers "%s" which has already been registered by module "%s"`, ErrDefinitionConflict, module, id, owner)
+}
+
+// describeSource returns the source along with the module which registered the definition if there is any
+func describeSource(source uintptr, module string) string {
+	if module == "" {
+		return formatSource(sourceOf(source))
+	}
+
+	return fmt.Sprintf(`%s by module "%s"`, formatSource(sourceOf(source)), module)
+}
+
+// caller returns the program counter of the caller if enabled, skip is relative to the caller of caller()
+func (b *DefaultBuilder) caller(skip int) uintptr {
+	if b.noSources {
+		return 0
+	}
+
+	return captureSource(skip + 1)
+}
+
+// builtin returns a ServiceDef for built-in services which have no source
+func builtin(id string, instance any) ServiceDef {
+	return &serviceDef{
+		definition: definition{
+			id: id,
+		},
+		factory: WithInstance(instance),
+	}
+}
@@ -29,3 +29,39 @@ func TestDefaultBuilder_Add(t *testing.T) {
 	assert.True(t, b.Has("def2"))
 	assert.Same(t, def2.Value(), b.Get("def2").(ParamDef).Value())
 }
+
+func TestDefaultBuilder_AddDuplicate(t *testing.T) {
+	b := Builder(
+		Param("def1", "a"),
+		Param("def1", "b"),
+	)
+	b.Add(Param("def2", "a"))
+	b.Add(Param("def2", "b"))
+	b.Add(Param("context", "c"))
+
+	_, err := b.Build(context.TODO())
+	assert.ErrorIs(t, err, ErrDefinitionConflict)
+	assert.Regexp(t, `"def1" registered at .*/builder_test.go:\d+ has already been registered at .*/builder_test.go:\d+`, err.Error())
+	assert.Regexp(t, `"def2" registered at .*/builder_test.go:\d+ has already been registered at .*/builder_test.go:\d+`, err.Error())
+	assert.Contains(t, err.Error(), `"context" registered at <unknown> has already been registered at`)
+	assert.Equal(t, "a", b.Get("def1").(ParamDef).Value())
+	assert.Equal(t, "a", b.Get("def2").(ParamDef).Value())
+}
+
+func TestDefaultBuilder_Override(t *testing.T) {
+	b := Builder(
+		Param("def1", "a"),
+		Param("def2", "b"),
+	)
+	b.Override(Param("def1", "c"))
+	b.Override(Param("def3", "d"))
+
+	c := b.MustBuild(context.TODO())
+	assert.Equal(t, "c", c.MustGet("def1"))
+	assert.Equal(t, "d", c.MustGet("def3"))
+	assert.Equal(t, []string{"container", "def1", "def2", "def3", "context"}, c.getOrder())
+
+	// building twice must not report the context as duplicate
+	_, err := b.Build(context.TODO())
+	assert.NoError(t, err)
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// method describes a single method of the interface to be proxied
+type method struct {
+	name     string
+	params   []string
+	results  []string
+	variadic bool
+	withCtx  bool
+}
+
+// generator collects the methods of an interface and the imports needed to render its proxy
+type generator struct {
+	fset    *token.FileSet
+	files   []*ast.File
+	methods []method
+	imports []string
+}
+
+// generate returns the source code of the proxy for the interface by given name declared in the package at dir
+func generate(dir, typeName string) ([]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{
+		fset:    token.NewFileSet(),
+		files:   make([]*ast.File, 0),
+		methods: make([]method, 0),
+		imports: make([]string, 0),
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(g.fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		g.files = append(g.files, file)
+	}
+
+	file, iface := g.findInterface(typeName)
+	if iface == nil {
+		return nil, fmt.Errorf(`cannot find interface "%s" in "%s"`, typeName, dir)
+	}
+
+	if err = g.collect(file, iface); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(g.methods, func(i, j int) bool {
+		return g.methods[i].name < g.methods[j].name
+	})
+
+	return g.render(file.Name.Name, typeName)
+}
+
+// findInterface returns the declaration of the non-generic interface by given name and the file declaring it
+func (g *generator) findInterface(typeName string) (*ast.File, *ast.InterfaceType) {
+	for _, file := range g.files {
+		var iface *ast.InterfaceType
+		ast.Inspect(file, func(node ast.Node) bool {
+			spec, ok := node.(*ast.TypeSpec)
+			if !ok || spec.Name.Name != typeName {
+				return iface == nil
+			}
+
+			if t, ok := spec.Type.(*ast.InterfaceType); ok && spec.TypeParams == nil {
+				iface = t
+			}
+
+			return false
+		})
+
+		if iface != nil {
+			return file, iface
+		}
+	}
+
+	return nil, nil
+}
+
+// collect adds the methods of the interface and the imports of the file they refer to
+func (g *generator) collect(file *ast.File, iface *ast.InterfaceType) error {
+	g.addImports(file, iface)
+
+	for _, field := range iface.Methods.List {
+		fn, ok := field.Type.(*ast.FuncType)
+		if !ok {
+			// embedded interfaces are supported as long as they are declared in the same package
+			ident, ok := field.Type.(*ast.Ident)
+			if !ok {
+				return fmt.Errorf(`embedded interface "%s" is not supported`, g.exprString(field.Type))
+			}
+
+			embeddedFile, embedded := g.findInterface(ident.Name)
+			if embedded == nil {
+				return fmt.Errorf(`cannot find embedded interface "%s"`, ident.Name)
+			}
+
+			if err := g.collect(embeddedFile, embedded); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		m := method{}
+		for _, param := range fn.Params.List {
+			typ := param.Type
+			if ellipsis, ok := typ.(*ast.Ellipsis); ok {
+				m.variadic = true
+				typ = &ast.ArrayType{Elt: ellipsis.Elt}
+			}
+
+			for i := 0; i < fieldCount(param); i++ {
+				m.params = append(m.params, g.exprString(typ))
+			}
+		}
+
+		if fn.Results != nil {
+			for _, result := range fn.Results.List {
+				for i := 0; i < fieldCount(result); i++ {
+					m.results = append(m.results, g.exprString(result.Type))
+				}
+			}
+		}
+
+		m.withCtx = len(m.params) > 0 && m.params[0] == "context.Context"
+		for _, name := range field.Names {
+			m.name = name.Name
+			g.methods = append(g.methods, m)
+		}
+	}
+
+	return nil
+}
+
+// addImports adds the import specs of the file which are referenced by the interface
+func (g *generator) addImports(file *ast.File, iface *ast.InterfaceType) {
+	used := make(map[string]bool)
+	ast.Inspect(iface, func(node ast.Node) bool {
+		if sel, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+
+		return true
+	})
+
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+
+		imp := strings.TrimSpace(fmt.Sprintf(`%s %s`, nameOf(spec), spec.Path.Value))
+		if used[name] && importPath != "context" && !contains(g.imports, imp) {
+			g.imports = append(g.imports, imp)
+		}
+	}
+}
+
+// render returns the formatted source code of the proxy
+func (g *generator) render(pkgName, typeName string) ([]byte, error) {
+	proxy := fmt.Sprintf(`%sProxy`, typeName)
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by dimple-proxy. DO NOT EDIT.\n\npackage %s\n\n", pkgName)
+	fmt.Fprintf(buf, "import (\n\t\"context\"\n")
+	for _, spec := range g.imports {
+		if !strings.Contains(spec, ".") {
+			fmt.Fprintf(buf, "\t%s\n", spec)
+		}
+	}
+
+	fmt.Fprintf(buf, "\n\t\"github.com/phramz/dimple\"\n")
+	for _, spec := range g.imports {
+		if strings.Contains(spec, ".") {
+			fmt.Fprintf(buf, "\t%s\n", spec)
+		}
+	}
+
+	fmt.Fprintf(buf, ")\n\n")
+	fmt.Fprintf(buf, "func init() {\n\tdimple.RegisterProxy[%s](New%s)\n}\n\n", typeName, proxy)
+	fmt.Fprintf(buf, "// %s calls the interceptor around each method of %s\n", proxy, typeName)
+	fmt.Fprintf(buf, "type %s struct {\n\tctx context.Context\n\ttarget %s\n\tinterceptor dimple.Interceptor\n}\n\n", proxy, typeName)
+	fmt.Fprintf(buf, "// New%s returns a new %s\n", proxy, proxy)
+	fmt.Fprintf(buf, "func New%s(ctx context.Context, target %s, interceptor dimple.Interceptor) %s {\n", proxy, typeName, typeName)
+	fmt.Fprintf(buf, "\treturn &%s{ctx: ctx, target: target, interceptor: interceptor}\n}\n", proxy)
+
+	for _, m := range g.methods {
+		params := make([]string, 0, len(m.params))
+		args := make([]string, 0, len(m.params))
+		for i, typ := range m.params {
+			arg := fmt.Sprintf(`a%d`, i)
+			args = append(args, arg)
+			if m.variadic && i == len(m.params)-1 {
+				typ = fmt.Sprintf(`...%s`, strings.TrimPrefix(typ, "[]"))
+				arg = fmt.Sprintf(`%s...`, arg)
+			}
+
+			params = append(params, fmt.Sprintf(`a%d %s`, i, typ))
+			args[i] = arg
+		}
+
+		results := make([]string, 0, len(m.results))
+		for i := range m.results {
+			results = append(results, fmt.Sprintf(`r%d`, i))
+		}
+
+		ctx := "p.ctx"
+		if m.withCtx {
+			ctx = "a0"
+		}
+
+		fmt.Fprintf(buf, "\nfunc (p *%s) %s(%s) (%s) {\n", proxy, m.name, strings.Join(params, ", "), strings.Join(m.results, ", "))
+		invoke := fmt.Sprintf("p.interceptor.Invoke(%s, %q, []any{%s}, func() []any {\n", ctx, m.name, strings.TrimSuffix(strings.Join(args, ", "), "..."))
+		if len(results) == 0 {
+			fmt.Fprintf(buf, "\t%s\t\tp.target.%s(%s)\n\n\t\treturn nil\n\t})\n}\n", invoke, m.name, strings.Join(args, ", "))
+			continue
+		}
+
+		fmt.Fprintf(buf, "\tout := %s", invoke)
+
+		fmt.Fprintf(buf, "\t\t%s := p.target.%s(%s)\n\n", strings.Join(results, ", "), m.name, strings.Join(args, ", "))
+		fmt.Fprintf(buf, "\t\treturn []any{%s}\n\t})\n\n", strings.Join(results, ", "))
+		for i, typ := range m.results {
+			fmt.Fprintf(buf, "\tr%d, _ := out[%d].(%s)\n", i, i, typ)
+		}
+
+		fmt.Fprintf(buf, "\n\treturn %s\n}\n", strings.Join(results, ", "))
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("cannot format generated source: %w\n%s", err, buf.String())
+	}
+
+	return src, nil
+}
+
+func fieldCount(field *ast.Field) int {
+	if len(field.Names) == 0 {
+		return 1
+	}
+
+	return len(field.Names)
+}
+
+func nameOf(spec *ast.ImportSpec) string {
+	if spec.Name == nil {
+		return ""
+	}
+
+	return spec.Name.Name
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *generator) exprString(expr ast.Expr) string {
+	buf := &bytes.Buffer{}
+	_ = printer.Fprint(buf, g.fset, expr)
+
+	return buf.String()
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	expected, err := os.ReadFile("testdata/repository_proxy.go.golden")
+	assert.NoError(t, err)
+
+	actual, err := generate("testdata", "Repository")
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(actual))
+}
+
+func TestGenerateUnknownInterface(t *testing.T) {
+	_, err := generate("testdata", "User")
+	assert.ErrorContains(t, err, `cannot find interface "User"`)
+}
+
+func TestGenerateUnsupportedEmbedding(t *testing.T) {
+	_, err := generate("testdata", "ReadCloser")
+	assert.ErrorContains(t, err, `embedded interface "io.Reader" is not supported`)
+}
@@ -0,0 +1,44 @@
+// Command dimple-proxy generates proxies for interfaces to intercept their method calls using dimple.Intercept.
+//
+// Usage:
+//
+//	//go:generate go run github.com/phramz/dimple/cmd/dimple-proxy -type Repository
+//
+// It will write the file repository_proxy.go next to the interface declaration containing the RepositoryProxy type
+// which is registered via dimple.RegisterProxy on init.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	typeName := flag.String("type", "", "name of the interface to generate a proxy for (required)")
+	dir := flag.String("dir", ".", "directory of the package declaring the interface")
+	output := flag.String("output", "", "output file name (default <type>_proxy.go)")
+	flag.Parse()
+
+	if *typeName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *output == "" {
+		*output = filepath.Join(*dir, fmt.Sprintf(`%s_proxy.go`, strings.ToLower(*typeName)))
+	}
+
+	src, err := generate(*dir, *typeName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dimple-proxy: %s\n", err)
+		os.Exit(1)
+	}
+
+	if err = os.WriteFile(*output, src, 0o644); err != nil { //nolint:gosec // generated source code is meant to be readable
+		fmt.Fprintf(os.Stderr, "dimple-proxy: %s\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,29 @@
+package testdata
+
+import (
+	"context"
+	"io"
+	tm "time"
+)
+
+type User struct {
+	Name    string
+	Created tm.Time
+}
+
+type Closer interface {
+	Close() error
+}
+
+type Repository interface {
+	Closer
+	Find(ctx context.Context, id int) (*User, error)
+	Save(u *User) error
+	Export(w io.Writer, ids ...int)
+	Count() int
+}
+
+type ReadCloser interface {
+	io.Reader
+	Closer
+}
@@ -0,0 +1,247 @@
+package dimple
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// compilation holds the frozen state of a compiled container. Its maps and slices are never written after
+// compilation, so they can be read without locking.
+type compilation struct {
+	order  []string
+	slots  map[string]int
+	public []bool
+	values []atomic.Pointer[compiledValue]
+	stats  []*serviceStats
+}
+
+type compiledValue struct {
+	value any
+}
+
+// Compile freezes the definitions of the container, so that no definition can be overridden anymore.
+// Definitions are sorted topologically by their known dependencies and each ID gets a slot which serves
+// already instantiated services without locking.
+func (c *DefaultContainer) Compile() error {
+	if c.parent != nil {
+		return c.parent.Compile()
+	}
+
+	if err := c.rewire(); err != nil {
+		return err
+	}
+
+	ids, edges, err := c.getDependencyGraph()
+	if err != nil {
+		return err
+	}
+
+	order, err := sortTopological(ids, edges)
+	if err != nil {
+		return err
+	}
+
+	cc := &compilation{
+		order:  order,
+		slots:  make(map[string]int, len(order)),
+		public: make([]bool, len(order)),
+		values: make([]atomic.Pointer[compiledValue], len(order)),
+		stats:  make([]*serviceStats, len(order)),
+	}
+
+	c.Lock()
+	for slot, id := range order {
+		cc.stats[slot] = c.getStats(id)
+	}
+	c.Unlock()
+
+	for slot, id := range order {
+		def := c.lookup(id)
+		cc.slots[id] = slot
+		cc.public[slot] = !def.Private()
+
+		switch t := def.(type) {
+		case ParamDef:
+			cc.values[slot].Store(&compiledValue{value: t.Value()})
+		case ServiceDef:
+			if c.getDefinition(id) == def && t.Instance() != nil {
+				cc.values[slot].Store(&compiledValue{value: t.Instance()})
+			}
+		}
+	}
+
+	c.compiled.Store(cc)
+
+	return nil
+}
+
+// getCompiled returns the value of the service by given id if it has been instantiated already
+func (c *DefaultContainer) getCompiled(id string) (any, bool) {
+	if c.parent != nil {
+		return nil, false
+	}
+
+	cc := c.compiled.Load()
+	if cc == nil {
+		return nil, false
+	}
+
+	slot, ok := cc.slots[id]
+	if !ok || !cc.public[slot] {
+		return nil, false
+	}
+
+	if val := cc.values[slot].Load(); val != nil {
+		cc.stats[slot].resolutions.Add(1)
+
+		return val.value, true
+	}
+
+	return nil, false
+}
+
+// storeCompiled stores the value of the service by given id, so it will be served without locking from now on
+func (c *DefaultContainer) storeCompiled(id string, value any) {
+	if c.parent != nil {
+		return
+	}
+
+	cc := c.compiled.Load()
+	if cc == nil {
+		return
+	}
+
+	if slot, ok := cc.slots[id]; ok && cc.public[slot] {
+		cc.values[slot].CompareAndSwap(nil, &compiledValue{value: value})
+	}
+}
+
+// replaceCompiled replaces the values of the given IDs. A nil value makes them being resolved again on next use.
+func (c *DefaultContainer) replaceCompiled(value *compiledValue, ids ...string) {
+	cc := c.compiled.Load()
+	if cc == nil {
+		return
+	}
+
+	for _, id := range ids {
+		if slot, ok := cc.slots[id]; ok {
+			cc.values[slot].Store(value)
+		}
+	}
+}
+
+// getBootOrder returns the IDs in topological order if the container has been compiled or in order of registration
+func (c *DefaultContainer) getBootOrder() []string {
+	if cc := c.compiled.Load(); cc != nil {
+		return cc.order
+	}
+
+	return c.getOrder()
+}
+
+func (c *DefaultContainer) isCompiled() bool {
+	if c.parent != nil {
+		return c.parent.isCompiled()
+	}
+
+	return c.compiled.Load() != nil
+}
+
+// sortTopological returns the IDs ordered by their dependencies given by edges
+func sortTopological(ids []string, edges map[string][]string) ([]string, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int, len(ids))
+	order := make([]string, 0, len(ids))
+	path := make([]string, 0)
+
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf(`%w: %s -> %s`, ErrCircularDependency, strings.Join(path, " -> "), id)
+		}
+
+		state[id] = visiting
+		path = append(path, id)
+		for _, dep := range edges[id] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+		order = append(order, id)
+
+		return nil
+	}
+
+	for _, id := range ids {
+		if err := visit(id); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+// getDependencyGraph returns all IDs in order of registration along with the IDs each of them is known to depend on.
+// Those are the decorator chains, the arguments of method calls and the dependencies recorded so far.
+func (c *DefaultContainer) getDependencyGraph() ([]string, map[string][]string, error) {
+	ids := c.getOrder()
+	edges := make(map[string][]string, len(ids))
+	for _, id := range ids {
+		deps, err := c.getStaticDependencies(id)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		edges[id] = deps
+	}
+
+	return ids, edges, nil
+}
+
+// getStaticDependencies returns the IDs the definition by given id is known to depend on
+func (c *DefaultContainer) getStaticDependencies(id string) ([]string, error) {
+	def := c.getDefinition(id)
+	if dec, ok := def.(DecoratorDef); ok && dec.Id() != id {
+		// the decorated service ID is an alias for its outermost decorator
+		return []string{dec.Id()}, nil
+	}
+
+	deps := c.getDependencies(id)
+	if dec, ok := def.(DecoratorDef); ok {
+		if inner, ok := dec.Decorated().(DecoratorDef); ok {
+			deps = append(deps, inner.Id())
+		} else {
+			// the origin service is instantiated by the innermost decorator
+			def = dec.Decorated()
+		}
+	}
+
+	svc, ok := def.(ServiceDef)
+	if !ok {
+		return deps, nil
+	}
+
+	for _, call := range svc.Calls() {
+		for _, arg := range call.Args {
+			dep := c.resolveID(svc.Namespace(), arg)
+			if !c.Has(dep) {
+				return nil, fmt.Errorf(`%w: "%s" called by "%s" with argument "%s"%s`, ErrUnknownService, call.Method, id, arg, declaredAt(svc))
+			}
+
+			deps = append(deps, dep)
+		}
+	}
+
+	return deps, nil
+}
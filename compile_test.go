@@ -0,0 +1,218 @@
+// nolint
+package dimple
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type bootRecorder struct {
+	sync.Mutex
+	booted []string
+}
+
+func (r *bootRecorder) record(id string) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.booted = append(r.booted, id)
+}
+
+type callableService struct {
+	dep any
+}
+
+func (c *callableService) SetDep(dep any) {
+	c.dep = dep
+}
+
+func TestContainer_Compile(t *testing.T) {
+	r := &bootRecorder{}
+	ctn := Builder(
+		Service("service.a", WithFn(func() any {
+			r.record("service.a")
+			return &callableService{}
+		})).WithCall("SetDep", "service.b"),
+		Service("service.b", WithFn(func() any {
+			r.record("service.b")
+			return &randomService{Name: "B"}
+		})),
+		Decorator("decorator.b", "service.b", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			r.record("decorator.b")
+			return ctx.Decorated(), nil
+		})),
+		Param("param.a", "A"),
+	).
+		MustBuild(context.TODO())
+
+	assert.NoError(t, ctn.Compile())
+	assert.NoError(t, ctn.Boot())
+
+	// dependencies are booted first
+	assert.Equal(t, []string{"service.b", "decorator.b", "service.a"}, r.booted)
+
+	a := ctn.MustGet("service.a")
+	assert.Same(t, ctn.MustGet("service.b"), a.(*callableService).dep)
+	assert.Same(t, a, ctn.MustGet("service.a"))
+	assert.Equal(t, "A", ctn.MustGet("param.a"))
+
+	assert.Panics(t, func() {
+		ctn.Override(Param("param.a", "B"))
+	})
+	assert.Equal(t, "A", ctn.MustGet("param.a"))
+
+	// a fork is not compiled and may be overridden
+	fork := ctn.Fork()
+	fork.Override(Param("param.a", "B"))
+	assert.Equal(t, "B", fork.MustGet("param.a"))
+	assert.Equal(t, "A", ctn.MustGet("param.a"))
+}
+
+func TestContainer_CompileBuilder(t *testing.T) {
+	b := Builder(Service("service.a", WithInstance(&randomService{Name: "A"})))
+	ctn := b.MustBuild(context.TODO())
+	assert.NoError(t, ctn.Compile())
+
+	assert.Panics(t, func() {
+		b.Override(Service("service.a", WithInstance(&randomService{Name: "B"})))
+	})
+	assert.Panics(t, func() {
+		b.Add(Param("param.a", "A"))
+	})
+	assert.Equal(t, "A", ctn.MustGet("service.a").(*randomService).Name)
+	assert.False(t, ctn.Has("param.a"))
+}
+
+func TestContainer_CompileErrors(t *testing.T) {
+	ctn := Builder(
+		Service("service.a", WithInstance(&callableService{})).WithCall("SetDep", "service.unknown"),
+	).
+		MustBuild(context.TODO())
+
+	assert.ErrorIs(t, ctn.Compile(), ErrUnknownService)
+
+	ctn = Builder(
+		Service("service.a", WithInstance(&callableService{})).WithCall("SetDep", "service.b"),
+		Service("service.b", WithInstance(&callableService{})).WithCall("SetDep", "service.a"),
+	).
+		MustBuild(context.TODO())
+
+	err := ctn.Compile()
+	assert.ErrorIs(t, err, ErrCircularDependency)
+	assert.ErrorContains(t, err, "service.a -> service.b -> service.a")
+}
+
+func TestContainer_CompilePrivate(t *testing.T) {
+	b := Builder()
+	assert.NoError(t, b.Install(Module{
+		Name:      "auth",
+		Namespace: "auth",
+		Definitions: []Definition{
+			Service("store", WithInstance(&randomService{})).WithPrivate(),
+		},
+	}))
+	ctn := b.MustBuild(context.TODO())
+	assert.NoError(t, ctn.Compile())
+
+	_, err := ctn.Get("auth.store")
+	assert.ErrorIs(t, err, ErrPrivateService)
+}
+
+func TestContainer_CompileConcurrent(t *testing.T) {
+	r := &bootRecorder{}
+	ctn := Builder(
+		Service("service.a", WithFn(func() any {
+			r.record("service.a")
+			return &callableService{}
+		})).WithCall("SetDep", "service.b"),
+		Service("service.b", WithFn(func() any {
+			r.record("service.b")
+			return &randomService{Name: "B"}
+		})),
+		Decorator("decorator.b", "service.b", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			r.record("decorator.b")
+			return ctx.Decorated(), nil
+		})),
+		Param("param.a", "A"),
+	).
+		MustBuild(context.TODO())
+
+	assert.NoError(t, ctn.Compile())
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = ctn.MustGet("service.a")
+			_ = ctn.MustGet("service.b")
+		}()
+	}
+	wg.Wait()
+
+	assert.Same(t, ctn.MustGet("service.b"), ctn.MustGet("service.a").(*callableService).dep)
+}
+
+func BenchmarkContainer_Get(b *testing.B) {
+	r := &bootRecorder{}
+	ctn := Builder(
+		Service("service.a", WithFn(func() any {
+			r.record("service.a")
+			return &callableService{}
+		})).WithCall("SetDep", "service.b"),
+		Service("service.b", WithFn(func() any {
+			r.record("service.b")
+			return &randomService{Name: "B"}
+		})),
+		Decorator("decorator.b", "service.b", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			r.record("decorator.b")
+			return ctx.Decorated(), nil
+		})),
+		Param("param.a", "A"),
+	).
+		MustBuild(context.TODO())
+
+	_ = ctn.Boot()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ctn.Get("service.b"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkContainer_GetCompiled(b *testing.B) {
+	r := &bootRecorder{}
+	ctn := Builder(
+		Service("service.a", WithFn(func() any {
+			r.record("service.a")
+			return &callableService{}
+		})).WithCall("SetDep", "service.b"),
+		Service("service.b", WithFn(func() any {
+			r.record("service.b")
+			return &randomService{Name: "B"}
+		})),
+		Decorator("decorator.b", "service.b", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			r.record("decorator.b")
+			return ctx.Decorated(), nil
+		})),
+		Param("param.a", "A"),
+	).
+		MustBuild(context.TODO())
+
+	_ = ctn.Compile()
+	_ = ctn.Boot()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ctn.Get("service.b"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -2,10 +2,15 @@ package dimple
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/thoas/go-funk"
 )
@@ -14,12 +19,43 @@ var _ Container = (*DefaultContainer)(nil)
 
 type DefaultContainer struct {
 	sync.Mutex
-	booted      bool
-	order       []string
-	ref         *string
-	parent      *DefaultContainer
-	ctx         context.Context
-	definitions map[string]Definition
+	booted       bool
+	order        []string
+	ref          *string
+	parent       *DefaultContainer
+	ctx          context.Context
+	caller       context.Context
+	definitions  map[string]Definition
+	shared       bool
+	dependencies map[string][]string
+	instances    []instanceRef
+	compiled     atomic.Pointer[compilation]
+	flights      map[string]*flight
+	waiting      map[string]string
+	listeners    []Listener
+	tracer       Tracer
+	stats        map[string]*serviceStats
+	booting      atomic.Int32
+	eager        atomic.Bool
+	reloading    sync.Mutex
+	watchers     []*watcher
+	base         *DefaultContainer
+	scopeTag     string
+	scoped       map[string]bool
+	probeTypes   bool
+}
+
+// flight represents the instantiation of a service which is in progress
+type flight struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+// instanceRef references an instance created by the container
+type instanceRef struct {
+	id       string
+	instance any
 }
 
 // MustGetT generic wrapper for Container.MustGet
@@ -38,34 +74,24 @@ func (c *DefaultContainer) Inject(target any) error {
 		return fmt.Errorf(`unable to inject to target. it has to been a pointer to a struct an addressable, got "%T"`, target)
 	}
 
-	v := reflect.ValueOf(target).Elem()
-	for i := 0; i < v.NumField(); i++ {
-		typeField := v.Type().Field(i)
-		if id, ok := typeField.Tag.Lookup("inject"); ok {
-			instance, err := c.Get(id)
-			if err != nil {
-				return err
-			}
-
-			fieldVal := v.Field(i)
-			if !fieldVal.CanSet() {
-				return fmt.Errorf(`unable to inject value to field "%s" since it is not writable`, typeField.Name)
-			}
-
-			fieldVal.Set(reflect.ValueOf(instance))
-		}
-	}
-
-	return nil
+	return getInjectPlan(reflect.TypeOf(target)).apply(c, reflect.ValueOf(target))
 }
 
 func (c *DefaultContainer) Boot() error {
-	// decorated services must be instantiated first
-	if err := c.boot(c.getAllDecoratorIDs()...); err != nil {
-		return err
-	}
+	root := c.getRoot()
+	root.eager.Store(true)
+	root.booting.Add(1)
+	defer root.booting.Add(-1)
+
+	start := time.Now()
+	ids := c.getAllServiceIDs()
+	booted, err := c.boot(ids...)
+
+	c.notify(func(l Listener) {
+		l.AfterBoot(booted, time.Since(start), err)
+	})
 
-	return c.boot(c.getAllServiceIDs()...)
+	return err
 }
 
 func (c *DefaultContainer) Has(id string) bool {
@@ -81,11 +107,22 @@ func (c *DefaultContainer) MustGet(id string) any {
 	return instance
 }
 
+func (c *DefaultContainer) GetCtx(ctx context.Context, id string) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, newCancelledError(id, err)
+	}
+
+	return c.withContext(ctx).Get(id)
+}
+
 func (c *DefaultContainer) Get(id string) (any, error) {
-	if !c.booted {
-		if err := c.boot(c.getAllDecoratorIDs()...); err != nil {
-			panic(err)
-		}
+	if instance, ok := c.getCompiled(id); ok {
+		return instance, nil
+	}
+
+	id = c.resolveID(c.getNamespace(), id)
+	if def := c.lookup(id); !c.isVisible(def) {
+		return nil, fmt.Errorf(`%w: "%s" can only be used within namespace "%s"`, ErrPrivateService, id, def.Namespace())
 	}
 
 	instance, err := c.getValue(id)
@@ -93,6 +130,8 @@ func (c *DefaultContainer) Get(id string) (any, error) {
 		return nil, err
 	}
 
+	c.storeCompiled(id, instance)
+
 	return instance, nil
 }
 
@@ -100,32 +139,188 @@ func (c *DefaultContainer) Ctx() context.Context {
 	return c.ctx
 }
 
-func (c *DefaultContainer) boot(ids ...string) error {
+func (c *DefaultContainer) Shutdown(ctx context.Context) error {
 	if c.parent != nil {
-		return nil
+		return c.parent.Shutdown(ctx)
 	}
 
-	defer func() {
-		c.booted = true
-	}()
+	start := time.Now()
+
+	c.Lock()
+	instances := c.instances
+	c.instances = nil
+	c.Unlock()
+
+	errs := make([]error, 0)
+	for i := len(instances) - 1; i >= 0; i-- {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		err := closeInstance(ctx, instances[i].instance)
+		if err != nil {
+			errs = append(errs, fmt.Errorf(`cannot shutdown service "%s": %w`, instances[i].id, err))
+		}
+
+		c.notify(func(l Listener) {
+			l.OnShutdown(instances[i].id, err)
+		})
+	}
+
+	err := joinErrors(errs)
+	c.notify(func(l Listener) {
+		l.AfterShutdown(len(instances), time.Since(start), err)
+	})
+
+	return err
+}
+
+func (c *DefaultContainer) Override(def Definition) {
+	if c.isCompiled() {
+		panic(fmt.Sprintf(`cannot override "%s" since the container has been compiled`, def.Id()))
+	}
+
+	c.add(def.Id(), def)
+	if root := c.getRoot(); root.base != nil {
+		root.Lock()
+		root.scoped[def.Id()] = true
+		root.Unlock()
+	}
+
+	c.notify(func(l Listener) {
+		l.OnDefinitionAdded(def)
+	})
+}
+
+func (c *DefaultContainer) Fork() Container {
+	return c.fork(false)
+}
+
+func (c *DefaultContainer) ForkFresh() Container {
+	return c.fork(true)
+}
+
+func (c *DefaultContainer) Scope(tag string) Container {
+	return c.scope(tag)
+}
+
+func (c *DefaultContainer) DecoratorChain(id string) []string {
+	chain := make([]string, 0)
+	for _, dec := range c.getDecoratorChain(id) {
+		chain = append(chain, dec.Id())
+	}
+
+	return chain
+}
+
+func (c *DefaultContainer) Tagged(tag string) []string {
+	ids := make([]string, 0)
+	for _, id := range c.getOrder() {
+		def := c.getDefinition(id)
+		if dec, ok := def.(DecoratorDef); ok && dec.Id() != id {
+			def = c.getOrigin(dec)
+		}
+
+		svc, ok := def.(ServiceDef)
+		if !ok {
+			continue
+		}
+
+		for _, t := range svc.Tags() {
+			if t.Name == tag {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+
+	return ids
+}
 
-	for _, id := range c.order {
+func (c *DefaultContainer) Tags(id string) []Tag {
+	if svc, ok := c.lookup(id).(ServiceDef); ok {
+		return svc.Tags()
+	}
+
+	return nil
+}
+
+// boot instantiates the services by given ids and returns the number of services booted successfully
+func (c *DefaultContainer) boot(ids ...string) (int, error) {
+	if c.parent != nil {
+		return 0, nil
+	}
+
+	booted := 0
+	for _, id := range c.getBootOrder() {
 		if !funk.ContainsString(ids, id) {
 			continue
 		}
 
 		if _, err := c.getValue(id); err != nil {
-			return err
+			return booted, err
 		}
+
+		booted++
 	}
 
-	return nil
+	return booted, nil
 }
 
-func (c *DefaultContainer) add(id string, val any) *DefaultContainer {
+// rewire replaces the definition of each decorated service by its outermost decorator, so that
+// decorated services will be instantiated lazily on first use. The origin definition becomes the
+// decorated definition of the innermost decorator. Decorators are wired by priority
+// rather than by the order of registration.
+func (c *DefaultContainer) rewire() error {
+	if c.parent != nil || c.isBooted() {
+		return nil
+	}
+
+	// decorators of tagged services get registered for each service individually
+	for _, dec := range c.getDecorators() {
+		if dec.DecoratesTag() == "" {
+			continue
+		}
+
+		for _, id := range c.Tagged(dec.DecoratesTag()) {
+			c.set(fmt.Sprintf(`%s.%s`, dec.Id(), id), dec.WithID(fmt.Sprintf(`%s.%s`, dec.Id(), id)).WithDecorates(id).WithDecoratesTag(""))
+		}
+	}
+
+	for _, id := range c.getDecoratedIDs() {
+		chain := c.getDecoratorChain(id)
+
+		// the decorated service might be relative to the namespace of the decorators
+		target := c.resolveID(chain[0].Namespace(), id)
+		decorated := c.getDefinition(target)
+		if decorated == nil {
+			return fmt.Errorf(`%w: cannot decorate non existing service "%s"`, ErrUnknownService, id)
+		}
+
+		decorators := make([]string, 0, len(chain))
+		for _, dec := range chain {
+			decorated = dec.WithDecorates(target).WithDecorated(decorated)
+			c.set(dec.Id(), decorated)
+			decorators = append(decorators, dec.Id())
+		}
+
+		c.notify(func(l Listener) {
+			l.OnRewire(target, decorators)
+		})
+
+		// the outermost decorator keeps its own ID, so the decorated service ID becomes an alias for it
+		c.set(target, decorated)
+	}
+
 	c.Lock()
-	defer c.Unlock()
+	c.booted = true
+	c.Unlock()
 
+	return nil
+}
+
+func (c *DefaultContainer) add(id string, val any) *DefaultContainer {
 	var def Definition
 	switch t := val.(type) {
 	case DecoratorDef:
@@ -140,20 +335,59 @@ func (c *DefaultContainer) add(id string, val any) *DefaultContainer {
 		panic(fmt.Sprintf(`unsupported type getDefinition %T`, val))
 	}
 
-	if c.parent == nil {
-		c.definitions[id] = def
+	c.set(id, def)
+
+	return c
+}
+
+// set stores the definition by given id as it is
+func (c *DefaultContainer) set(id string, def Definition) {
+	if c.parent != nil {
+		c.parent.set(id, def)
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	if c.shared {
+		// the definitions are shared with a fork, so they need to be copied before being written
+		definitions := make(map[string]Definition, len(c.definitions)+1)
+		for k, v := range c.definitions {
+			definitions[k] = v
+		}
+
+		c.definitions = definitions
+		c.order = append(make([]string, 0, len(c.order)+1), c.order...)
+		c.shared = false
+	}
+
+	if _, ok := c.definitions[id]; !ok {
 		c.order = append(c.order, id)
-		return c
 	}
 
-	c.parent.add(id, def)
+	c.definitions[id] = def
+}
 
-	return c
+// getInstance calls the factory of the definition and notifies the listeners about it
+func (c *DefaultContainer) getInstance(def Definition, target any) (any, error) {
+	c.notify(func(l Listener) {
+		l.BeforeInstantiate(def.Id())
+	})
+
+	start := time.Now()
+	instance, err := c.createInstance(def, target)
+	duration := time.Since(start)
+
+	c.notify(func(l Listener) {
+		l.AfterInstantiate(def.Id(), instance, duration, err)
+	})
+
+	return instance, err
 }
 
-func (c *DefaultContainer) getInstance(def Definition) (any, error) {
-	var err error
-	var target, instance any
+func (c *DefaultContainer) createInstance(def Definition, target any) (any, error) {
+	var instance any
 	var f Factory
 
 	if svc, ok := def.(ServiceDef); ok {
@@ -165,14 +399,7 @@ func (c *DefaultContainer) getInstance(def Definition) (any, error) {
 	}
 
 	if f == nil {
-		return nil, fmt.Errorf(`%w: cannot instantiate service "%s" due to missing factory`, ErrServiceFactoryFailed, def.Id())
-	}
-
-	if svc, ok := def.(DecoratorDef); ok {
-		target, err = c.getValue(svc.Decorates())
-		if err != nil {
-			return nil, err
-		}
+		return nil, fmt.Errorf(`%w: cannot instantiate service "%s"%s due to missing factory`, ErrServiceFactoryFailed, def.Id(), declaredAt(def))
 	}
 
 	if instance = f.Instance(); instance != nil {
@@ -180,33 +407,34 @@ func (c *DefaultContainer) getInstance(def Definition) (any, error) {
 	}
 
 	if fn := f.FactoryFnWithContext(); fn != nil {
-		instance, err = fn(newFactoryCtx(c.ctx, c, target))
-		if err != nil {
-			return nil, fmt.Errorf(`%w: cannot instantiate service "%s: %s"`, ErrServiceFactoryFailed, def.Id(), err.Error())
-		}
-
-		return instance, err
+		return c.attempt(def, func(scoped *DefaultContainer) (any, error) {
+			return fn(newFactoryCtx(scoped.ctx, scoped, target))
+		})
 	}
 
 	if fn := f.FactoryFnWithError(); fn != nil {
-		instance, err = fn()
-		if err != nil {
-			return nil, fmt.Errorf(`%w: cannot instantiate service "%s: %s"`, ErrServiceFactoryFailed, def.Id(), err.Error())
-		}
+		timeout, _ := getPolicy(def)
 
-		return instance, err
+		return c.attempt(def, func(scoped *DefaultContainer) (any, error) {
+			if timeout <= 0 {
+				return fn()
+			}
+
+			// the factory cannot be cancelled, so it will be abandoned once the deadline has passed
+			return callWithDeadline(scoped.ctx, fn)
+		})
 	}
 
 	if fn := f.FactoryFn(); fn != nil {
 		instance = fn()
 		if instance == nil {
-			return nil, fmt.Errorf(`%w: cannot instantiate service "%s: factory returned nil"`, ErrServiceFactoryFailed, def.Id())
+			return nil, fmt.Errorf(`%w: cannot instantiate service "%s: factory returned nil"%s`, ErrServiceFactoryFailed, def.Id(), declaredAt(def))
 		}
 
 		return instance, nil
 	}
 
-	return nil, fmt.Errorf(`%w: cannot instantiate service "%s: no factory function provided"`, ErrServiceFactoryFailed, def.Id())
+	return nil, fmt.Errorf(`%w: cannot instantiate service "%s: no factory function provided"%s`, ErrServiceFactoryFailed, def.Id(), declaredAt(def))
 }
 
 func (c *DefaultContainer) getValue(id string) (any, error) {
@@ -214,6 +442,22 @@ func (c *DefaultContainer) getValue(id string) (any, error) {
 		return nil, fmt.Errorf(`%w: cannot find definiton for service "%s"`, ErrUnknownService, id)
 	}
 
+	if c.ref != nil {
+		c.addDependency(*c.ref, id)
+	}
+
+	if root := c.getRoot(); root.base != nil && !root.isScoped(id) {
+		// services outside of the scope are shared with the container the scope has been created from
+		return root.base.getValue(id)
+	}
+
+	c.countResolution(id)
+
+	return c.resolve(id)
+}
+
+// resolve returns the value of the definition by given id
+func (c *DefaultContainer) resolve(id string) (any, error) {
 	def := c.getDefinition(id)
 
 	// if it's a ParamDef just return the value
@@ -225,16 +469,191 @@ func (c *DefaultContainer) getValue(id string) (any, error) {
 		return nil, fmt.Errorf(`%w: %s`, ErrCircularDependency, c.getDebugPathInfo(c.getPath(id)))
 	}
 
-	indirection := c.getIndirect(id)
-	if svc, ok := def.(ServiceDef); ok {
-		return indirection.getService(svc)
+	if dec, ok := def.(DecoratorDef); ok && dec.Id() != id {
+		// the decorated service ID is an alias for its outermost decorator
+		return c.resolve(dec.Id())
 	}
 
-	if svc, ok := def.(DecoratorDef); ok {
-		return indirection.getDecoration(svc)
+	if instance := instanceOf(def); instance != nil {
+		// already instantiated?
+		return instance, nil
 	}
 
-	panic(fmt.Sprintf(`unsupported type of definiton "%T" for service "%s"`, def, id))
+	if err := c.callerCtx().Err(); err != nil {
+		return nil, newCancelledError(id, err)
+	}
+
+	return c.once(id, func() (any, error) {
+		// the service might have been instantiated in the meantime
+		def := c.getDefinition(id)
+
+		indirection, end := c.getTracedIndirect(id, def)
+		done := c.measure(id)
+		switch svc := def.(type) {
+		case ServiceDef:
+			instance, err := indirection.getService(svc)
+			done(err)
+			end(err)
+
+			return instance, err
+		case DecoratorDef:
+			instance, err := indirection.getDecoration(svc)
+			done(err)
+			end(err)
+
+			return instance, err
+		default:
+			panic(fmt.Sprintf(`unsupported type of definiton "%T" for service "%s"`, def, id))
+		}
+	})
+}
+
+// once ensures that a service will be instantiated only once even if it is requested concurrently.
+// Concurrent requests wait for the instantiation in progress unless that would wait for themselves.
+func (c *DefaultContainer) once(id string, fn func() (any, error)) (any, error) {
+	root := c.getRoot()
+	path := c.getPath(id)
+
+	root.Lock()
+	if root.flights == nil {
+		root.flights = make(map[string]*flight)
+		root.waiting = make(map[string]string)
+	}
+
+	if f, ok := root.flights[id]; ok {
+		if root.isWaitingFor(id, path) {
+			root.Unlock()
+			return nil, fmt.Errorf(`%w: %s while being instantiated concurrently`, ErrCircularDependency, c.getDebugPathInfo(path))
+		}
+
+		owned := path[:len(path)-1]
+		for _, p := range owned {
+			root.waiting[p] = id
+		}
+		root.Unlock()
+
+		var err error
+		select {
+		case <-f.done:
+		case <-c.callerCtx().Done():
+			err = newCancelledError(id, c.callerCtx().Err())
+		}
+
+		root.Lock()
+		for _, p := range owned {
+			delete(root.waiting, p)
+		}
+		root.Unlock()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if errors.Is(f.err, ErrResolutionCancelled) && c.callerCtx().Err() == nil {
+			// the resolution in progress has been cancelled by its caller but not this one
+			return c.once(id, fn)
+		}
+
+		return f.value, f.err
+	}
+
+	f := &flight{done: make(chan struct{})}
+	root.flights[id] = f
+	root.Unlock()
+
+	completed := false
+	defer func() {
+		if !completed {
+			// the factory panicked
+			f.err = fmt.Errorf(`%w: instantiation of "%s" panicked`, ErrServiceFactoryFailed, id)
+		}
+
+		root.Lock()
+		delete(root.flights, id)
+		root.Unlock()
+
+		close(f.done)
+	}()
+
+	f.value, f.err = fn()
+	completed = true
+
+	return f.value, f.err
+}
+
+// isWaitingFor returns true if the instantiation of the service by given id waits for any of the services
+// of the given path to be instantiated. The caller has to hold the lock.
+func (c *DefaultContainer) isWaitingFor(id string, path []string) bool {
+	visited := make(map[string]bool)
+	for !visited[id] {
+		if funk.ContainsString(path[:len(path)-1], id) {
+			return true
+		}
+
+		visited[id] = true
+
+		next, ok := c.waiting[id]
+		if !ok {
+			return false
+		}
+
+		id = next
+	}
+
+	return false
+}
+
+// withContext returns a view of the container which resolves services within the given context
+func (c *DefaultContainer) withContext(ctx context.Context) *DefaultContainer {
+	if FromContext(ctx) == nil {
+		// factories must be able to reach the container from any context they receive
+		ctx = WithContainer(ctx, c.getRoot())
+	}
+
+	if c.parent == nil {
+		scoped := c.clone()
+		scoped.ctx = ctx
+		scoped.caller = ctx
+
+		return scoped
+	}
+
+	return &DefaultContainer{
+		ctx:    ctx,
+		caller: ctx,
+		ref:    c.ref,
+		parent: c.parent,
+	}
+}
+
+// callerCtx returns the context given to GetCtx or BootParallel. The context given at Build() does not abort
+// resolutions, since it might be done long before the services are requested.
+func (c *DefaultContainer) callerCtx() context.Context {
+	if c.caller == nil {
+		return context.Background()
+	}
+
+	return c.caller
+}
+
+func (c *DefaultContainer) getRoot() *DefaultContainer {
+	if c.parent != nil {
+		return c.parent.getRoot()
+	}
+
+	return c
+}
+
+// instanceOf returns the instance of the given definition if it has been instantiated already
+func instanceOf(def Definition) any {
+	switch t := def.(type) {
+	case DecoratorDef:
+		return t.Instance()
+	case ServiceDef:
+		return t.Instance()
+	default:
+		return nil
+	}
 }
 
 func (c *DefaultContainer) getDefinition(id string) Definition {
@@ -243,6 +662,9 @@ func (c *DefaultContainer) getDefinition(id string) Definition {
 		return c.parent.getDefinition(id)
 	}
 
+	c.Lock()
+	defer c.Unlock()
+
 	if def, ok := c.definitions[id]; ok {
 		return def
 	}
@@ -256,12 +678,19 @@ func (c *DefaultContainer) getDecoration(svc DecoratorDef) (any, error) {
 		return svc.Instance(), nil
 	}
 
-	// we need to instantiate a getInstance service
-	if c.isCircularDependency(svc.Decorates()) {
-		return nil, fmt.Errorf(`%w: %s`, ErrCircularDependency, c.getDebugPathInfo(c.getPath(svc.Decorates())))
+	target, decorated, err := c.getDecorated(svc)
+	if err != nil {
+		return nil, err
 	}
 
-	instance, err := c.getInstance(svc)
+	if cond := svc.Condition(); cond != nil && !cond(newFactoryCtx(c.ctx, c, target)) {
+		// the condition does not hold, so the decorated service will be passed through
+		c.add(svc.Id(), svc.WithInstance(target).WithDecorated(decorated))
+
+		return target, nil
+	}
+
+	instance, err := c.getInstance(svc, target)
 	if err != nil {
 		return nil, err
 	}
@@ -272,20 +701,60 @@ func (c *DefaultContainer) getDecoration(svc DecoratorDef) (any, error) {
 		}
 	}
 
-	if !c.Has(svc.Decorates()) {
-		return nil, fmt.Errorf(`%w: cannot decorate non existinmg service "%s"`, ErrUnknownService, svc.Decorates())
+	c.notify(func(l Listener) {
+		l.OnDecorate(svc.Decorates(), svc.Id())
+	})
+
+	if !isProvided(svc) {
+		c.track(svc.Id(), instance)
 	}
+	c.add(svc.Id(), svc.WithInstance(instance).WithDecorated(decorated))
 
-	targetDef := c.getDefinition(svc.Decorates())
-	if err != nil {
-		return nil, err
+	return instance, nil
+}
+
+// getDecorated returns the instance the given decorator wraps. That is either the next inner decorator
+// or the origin service which is then stored as part of the decorated definition.
+func (c *DefaultContainer) getDecorated(svc DecoratorDef) (any, Definition, error) {
+	if svc.DecoratesTag() != "" {
+		return nil, nil, fmt.Errorf(`%w: decorator "%s" of tag "%s" cannot be resolved directly`, ErrUnknownService, svc.Id(), svc.DecoratesTag())
 	}
 
-	target := svc.WithInstance(instance).WithDecorated(targetDef)
-	c.add(svc.Id(), target)
-	c.add(svc.Decorates(), target)
+	switch decorated := svc.Decorated().(type) {
+	case nil:
+		return nil, nil, fmt.Errorf(`%w: cannot decorate non existing service "%s"`, ErrUnknownService, svc.Decorates())
+	case ParamDef:
+		return decorated.Value(), decorated, nil
+	case DecoratorDef:
+		target, err := c.getValue(decorated.Id())
+
+		return target, decorated, err
+	case ServiceDef:
+		if decorated.Instance() != nil {
+			return decorated.Instance(), decorated, nil
+		}
 
-	return instance, nil
+		if c.isCircularDependency(svc.Decorates()) {
+			return nil, nil, fmt.Errorf(`%w: %s`, ErrCircularDependency, c.getDebugPathInfo(c.getPath(svc.Decorates())))
+		}
+
+		indirection, end := c.getTracedIndirect(svc.Decorates(), decorated)
+		done := c.measure(svc.Decorates())
+		target, err := indirection.instantiate(decorated)
+		done(err)
+		end(err)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !isProvided(decorated) {
+			c.track(svc.Decorates(), target)
+		}
+
+		return target, decorated.WithInstance(target), nil
+	default:
+		panic(fmt.Sprintf(`unsupported type of definiton "%T" for service "%s"`, decorated, svc.Decorates()))
+	}
 }
 
 func (c *DefaultContainer) getService(def ServiceDef) (any, error) {
@@ -294,8 +763,22 @@ func (c *DefaultContainer) getService(def ServiceDef) (any, error) {
 		return def.Instance(), nil
 	}
 
-	// we need to instantiate a getInstance service
-	instance, err := c.getInstance(def)
+	instance, err := c.instantiate(def)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isProvided(def) {
+		c.track(def.Id(), instance)
+	}
+	c.add(def.Id(), def.WithInstance(instance))
+
+	return instance, nil
+}
+
+// instantiate creates a new instance of the service and injects its dependencies
+func (c *DefaultContainer) instantiate(def ServiceDef) (any, error) {
+	instance, err := c.getInstance(def, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -306,11 +789,236 @@ func (c *DefaultContainer) getService(def ServiceDef) (any, error) {
 		}
 	}
 
-	c.add(def.Id(), def.WithInstance(instance))
+	for _, call := range def.Calls() {
+		if err = c.call(instance, call); err != nil {
+			return nil, fmt.Errorf(`cannot call "%s" on service "%s": %w`, call.Method, def.Id(), err)
+		}
+	}
 
 	return instance, nil
 }
 
+// call invokes the given Call on the instance and resolves its arguments by ID
+func (c *DefaultContainer) call(instance any, call Call) error {
+	method := reflect.ValueOf(instance).MethodByName(call.Method)
+	if !method.IsValid() {
+		return fmt.Errorf(`method "%s" does not exist on type "%T"`, call.Method, instance)
+	}
+
+	if method.Type().IsVariadic() || method.Type().NumIn() != len(call.Args) {
+		return fmt.Errorf(`method "%s" expects %d arguments, got %d`, call.Method, method.Type().NumIn(), len(call.Args))
+	}
+
+	args := make([]reflect.Value, 0, len(call.Args))
+	for i, id := range call.Args {
+		val, err := c.Get(id)
+		if err != nil {
+			return err
+		}
+
+		arg, ok := toValue(val, method.Type().In(i))
+		if !ok {
+			return fmt.Errorf(`value of "%s" of type "%T" is not assignable to argument %d of type "%s"`, id, val, i+1, method.Type().In(i))
+		}
+
+		args = append(args, arg)
+	}
+
+	return c.invoke(method, args)
+}
+
+// invoke calls the method and returns the error if the last return value is one
+func (c *DefaultContainer) invoke(method reflect.Value, args []reflect.Value) error {
+	out := method.Call(args)
+	if len(out) == 0 {
+		return nil
+	}
+
+	if err, ok := out[len(out)-1].Interface().(error); ok && err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// getValueByType returns the value of the only definition assignable to the given type. Only parameters and services
+// which have been instantiated already are considered, unless type probing is enabled. Then the remaining services
+// get instantiated if none of them matches, skipping those which fail.
+func (c *DefaultContainer) getValueByType(t reflect.Type) (reflect.Value, error) {
+	candidates := make([]string, 0)
+	for _, id := range c.getOrder() {
+		if dec, ok := c.getDefinition(id).(DecoratorDef); ok && dec.Decorates() != id {
+			// the decorated service will be considered instead
+			continue
+		}
+
+		if !c.isCircularDependency(id) {
+			candidates = append(candidates, id)
+		}
+	}
+
+	matches := make([]string, 0)
+	hidden := make([]string, 0)
+	unknown := make([]string, 0)
+	for _, id := range candidates {
+		visible := c.isVisible(c.lookup(id))
+		val, ok := c.getKnownValue(id)
+		if !ok {
+			if visible {
+				unknown = append(unknown, id)
+			}
+		} else if _, ok = toValue(val, t); ok && visible {
+			matches = append(matches, id)
+		} else if ok {
+			hidden = append(hidden, id)
+		}
+	}
+
+	failed := make([]string, 0)
+	if len(matches) == 0 && c.getRoot().probeTypes {
+		for _, id := range unknown {
+			// the dependency will be recorded only if the service matches
+			val, err := c.resolve(id)
+			if errors.Is(err, ErrResolutionCancelled) {
+				return reflect.Value{}, err
+			}
+
+			if err != nil {
+				failed = append(failed, id)
+			} else if _, ok := toValue(val, t); ok {
+				matches = append(matches, id)
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		if len(hidden) > 0 {
+			return reflect.Value{}, fmt.Errorf(`%w: "%s" of type "%s" can only be used within its namespace`, ErrPrivateService, strings.Join(hidden, `", "`), t)
+		}
+
+		if len(failed) > 0 {
+			return reflect.Value{}, fmt.Errorf(`%w: cannot find any definition of type "%s" while "%s" failed to instantiate`, ErrUnknownService, t, strings.Join(failed, `", "`))
+		}
+
+		if len(unknown) > 0 && !c.getRoot().probeTypes {
+			return reflect.Value{}, fmt.Errorf(`%w: cannot find any instantiated definition of type "%s", either boot the container or enable type probing`, ErrUnknownService, t)
+		}
+
+		return reflect.Value{}, fmt.Errorf(`%w: cannot find any definition of type "%s"`, ErrUnknownService, t)
+	case 1:
+		val, err := c.getValue(matches[0])
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		arg, _ := toValue(val, t)
+
+		return arg, nil
+	default:
+		return reflect.Value{}, fmt.Errorf(`%w: "%s" are all assignable to type "%s"`, ErrAmbiguousType, strings.Join(matches, `", "`), t)
+	}
+}
+
+// isVisible returns FALSE if the definition is private and not requested from within its namespace
+func (c *DefaultContainer) isVisible(def Definition) bool {
+	return def == nil || !def.Private() || (c.ref != nil && def.Namespace() == c.getNamespace())
+}
+
+// getKnownValue returns the value of the definition by given id if it is a parameter, a service registered by its
+// instance or has been instantiated already
+func (c *DefaultContainer) getKnownValue(id string) (any, bool) {
+	def := c.getDefinition(id)
+	if dec, ok := def.(DecoratorDef); ok && dec.Id() != id {
+		def = c.getDefinition(dec.Id())
+	}
+
+	switch t := def.(type) {
+	case ParamDef:
+		return t.Value(), true
+	case ServiceDef:
+		if t.Instance() == nil && t.Factory() != nil && t.Factory().Instance() != nil {
+			return t.Factory().Instance(), true
+		}
+	}
+
+	instance := instanceOf(def)
+
+	return instance, instance != nil
+}
+
+func (c *DefaultContainer) isBooted() bool {
+	if c.parent != nil {
+		return c.parent.isBooted()
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	return c.booted
+}
+
+func (c *DefaultContainer) getOrder() []string {
+	if c.parent != nil {
+		return c.parent.getOrder()
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	return append(make([]string, 0, len(c.order)), c.order...)
+}
+
+// getNamespace returns the namespace of the definition which is currently resolved
+func (c *DefaultContainer) getNamespace() string {
+	if c.ref == nil {
+		return ""
+	}
+
+	if def := c.lookup(*c.ref); def != nil {
+		return def.Namespace()
+	}
+
+	return ""
+}
+
+// resolveID returns the ID prefixed by the namespace if there is a definition within the namespace, otherwise the
+// given id. Definitions of the namespace take precedence over global definitions of the same ID.
+func (c *DefaultContainer) resolveID(namespace, id string) string {
+	if namespace == "" {
+		return id
+	}
+
+	if nsID := fmt.Sprintf(`%s.%s`, namespace, id); c.Has(nsID) {
+		return nsID
+	}
+
+	return id
+}
+
+// lookup returns the definition by given ID. For a decorated service it returns the origin definition
+// instead of its outermost decorator.
+func (c *DefaultContainer) lookup(id string) Definition {
+	def := c.getDefinition(id)
+	if dec, ok := def.(DecoratorDef); ok && dec.Id() != id {
+		return c.getOrigin(dec)
+	}
+
+	return def
+}
+
+// getOrigin returns the definition of the origin service of a decorator chain
+func (c *DefaultContainer) getOrigin(def Definition) Definition {
+	for {
+		dec, ok := def.(DecoratorDef)
+		if !ok {
+			return def
+		}
+
+		def = dec.Decorated()
+	}
+}
+
 func (c *DefaultContainer) getIndirect(id string) *DefaultContainer {
 	indirection := c.clone()
 	indirection.ref = &id
@@ -348,21 +1056,65 @@ func (c *DefaultContainer) getPath(id string) []string {
 	return path
 }
 
-func (c *DefaultContainer) getAllDecoratorIDs() []string {
-	return funk.FilterString(funk.Keys(c.definitions).([]string), func(s string) bool {
-		def := c.definitions[s]
-		_, ok := def.(DecoratorDef)
+// getDecorators returns all decorator definitions in order of registration. Definitions of decorated services
+// which have been rewritten to their decoration are skipped.
+func (c *DefaultContainer) getDecorators() []DecoratorDef {
+	decorators := make([]DecoratorDef, 0)
+	for _, id := range c.getOrder() {
+		if dec, ok := c.getDefinition(id).(DecoratorDef); ok && dec.Decorates() != id {
+			decorators = append(decorators, dec)
+		}
+	}
+
+	return decorators
+}
+
+// getDecoratedIDs returns the IDs of all decorated services
+func (c *DefaultContainer) getDecoratedIDs() []string {
+	ids := make([]string, 0)
+	for _, dec := range c.getDecorators() {
+		if dec.DecoratesTag() == "" && !funk.ContainsString(ids, dec.Decorates()) {
+			ids = append(ids, dec.Decorates())
+		}
+	}
+
+	return ids
+}
+
+// getDecoratorChain returns the decorators of the given service ordered by priority
+func (c *DefaultContainer) getDecoratorChain(id string) []DecoratorDef {
+	chain := make([]DecoratorDef, 0)
+	for _, dec := range c.getDecorators() {
+		if dec.DecoratesTag() == "" && dec.Decorates() == id {
+			chain = append(chain, dec)
+		}
+	}
 
-		return ok
+	sort.SliceStable(chain, func(i, j int) bool {
+		return chain[i].Priority() > chain[j].Priority()
 	})
+
+	return chain
 }
 
+// getAllServiceIDs returns the IDs of all services and decorators
 func (c *DefaultContainer) getAllServiceIDs() []string {
-	return funk.FilterString(funk.Keys(c.definitions).([]string), func(s string) bool {
-		def := c.definitions[s]
-		_, ok := def.(ServiceDef)
+	if c.parent != nil {
+		return c.parent.getAllServiceIDs()
+	}
+
+	c.Lock()
+	defer c.Unlock()
 
-		return ok
+	return funk.FilterString(funk.Keys(c.definitions).([]string), func(s string) bool {
+		switch def := c.definitions[s].(type) {
+		case ServiceDef:
+			return true
+		case DecoratorDef:
+			return def.DecoratesTag() == ""
+		default:
+			return false
+		}
 	})
 }
 
@@ -384,8 +1136,22 @@ func (c *DefaultContainer) isInjectable(target any) bool {
 
 func (c *DefaultContainer) getDebugPathInfo(defIDs []string) string {
 	pathInfo := make([]string, 0)
+	sources := make([]string, 0)
 	for _, defID := range defIDs {
-		subPath := c.getDebugDecoratorPath(c.getDefinition(defID))
+		def := c.getDefinition(defID)
+		if origin := c.lookup(defID); origin != nil && origin.Source() != "" {
+			source := fmt.Sprintf(`"%s" at %s`, defID, origin.Source())
+			if !funk.ContainsString(sources, source) {
+				sources = append(sources, source)
+			}
+		}
+
+		if dec, ok := def.(DecoratorDef); ok && dec.Id() != defID {
+			// the decorated service ID is just an alias for its outermost decorator
+			def = nil
+		}
+
+		subPath := c.getDebugDecoratorPath(def)
 		subPathInfo := ""
 
 		if len(subPath) > 0 {
@@ -395,7 +1161,11 @@ func (c *DefaultContainer) getDebugPathInfo(defIDs []string) string {
 		pathInfo = append(pathInfo, fmt.Sprintf(`"%s"%s`, defID, subPathInfo))
 	}
 
-	return strings.Join(pathInfo, ` -> `)
+	if len(sources) == 0 {
+		return strings.Join(pathInfo, ` -> `)
+	}
+
+	return fmt.Sprintf(`%s (declared %s)`, strings.Join(pathInfo, ` -> `), strings.Join(sources, `, `))
 }
 
 func (c *DefaultContainer) getDebugDecoratorPath(def Definition) []string {
@@ -418,10 +1188,102 @@ func (c *DefaultContainer) getDebugDecoratorPath(def Definition) []string {
 	return path
 }
 
+// isProvided returns true if the instance of the given definition has been passed in by WithInstance rather than
+// created by a factory. The container does not own such instances, so it never closes them.
+func isProvided(def Definition) bool {
+	switch t := def.(type) {
+	case DecoratorDef:
+		return t.Factory() != nil && t.Factory().Instance() != nil
+	case ServiceDef:
+		return t.Factory() != nil && t.Factory().Instance() != nil
+	default:
+		return false
+	}
+}
+
+// track records the instance created for the service by given id, so it can be closed on shutdown
+func (c *DefaultContainer) track(id string, instance any) {
+	if c.parent != nil {
+		c.parent.track(id, instance)
+		return
+	}
+
+	if _, ok := instance.(*DefaultContainer); ok {
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	for _, ref := range c.instances {
+		if isSameInstance(ref.instance, instance) {
+			// e.g. a decorator passing through the decorated service
+			return
+		}
+	}
+
+	c.instances = append(c.instances, instanceRef{id: id, instance: instance})
+}
+
+// addDependency records that the definition by given id depends on another
+func (c *DefaultContainer) addDependency(id, dependency string) {
+	if c.parent != nil {
+		c.parent.addDependency(id, dependency)
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	if c.dependencies == nil {
+		c.dependencies = make(map[string][]string)
+	}
+
+	if id != dependency && !funk.ContainsString(c.dependencies[id], dependency) {
+		c.dependencies[id] = append(c.dependencies[id], dependency)
+	}
+}
+
+// getDependencies returns the IDs of all definitions the definition by given id has depended on so far
+func (c *DefaultContainer) getDependencies(id string) []string {
+	if c.parent != nil {
+		return c.parent.getDependencies(id)
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	return append(make([]string, 0, len(c.dependencies[id])), c.dependencies[id]...)
+}
+
 func (c *DefaultContainer) clone() *DefaultContainer {
 	return &DefaultContainer{
-		ctx:         c.ctx,
-		definitions: c.definitions,
-		parent:      c,
+		ctx:    c.ctx,
+		caller: c.caller,
+		parent: c,
 	}
 }
+
+// closeInstance calls either Shutdown(ctx) or Close() if the instance implements it
+func closeInstance(ctx context.Context, instance any) error {
+	switch t := instance.(type) {
+	case interface {
+		Shutdown(ctx context.Context) error
+	}:
+		return t.Shutdown(ctx)
+	case io.Closer:
+		return t.Close()
+	case interface{ Close() }:
+		t.Close()
+	}
+
+	return nil
+}
+
+func isSameInstance(a, b any) bool {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) || reflect.TypeOf(a) == nil || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+
+	return a == b
+}
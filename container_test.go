@@ -3,7 +3,9 @@ package dimple
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -98,6 +100,7 @@ func TestCircularDependency(t *testing.T) {
 		if r := recover(); r != nil {
 			assert.ErrorIs(t, r.(error), ErrCircularDependency)
 			assert.Contains(t, r.(error).Error(), `"service.a" -> "service.b" -> "service.c" -> "service.a"`)
+			assert.Regexp(t, `\(declared "service.a" at .*/container_test.go:\d+, "service.b" at .*/container_test.go:\d+, "service.c" at .*/container_test.go:\d+\)`, r.(error).Error())
 			return
 		}
 
@@ -106,3 +109,371 @@ func TestCircularDependency(t *testing.T) {
 
 	_ = ctn.MustGet(serviceA).(*randomService)
 }
+
+type methodInjectableService struct {
+	a      randomInterface
+	name   string
+	called int
+}
+
+func (m *methodInjectableService) InjectA(a *randomService) {
+	m.a = a
+	m.called++
+}
+
+func (m *methodInjectableService) InjectName(name string) error {
+	m.name = name
+	m.called++
+
+	return nil
+}
+
+func TestContainer_InjectMethods(t *testing.T) {
+	const serviceA = "service.a"
+	const paramName = "param.name"
+
+	ctn := Builder(
+		Param(paramName, "foo"),
+		Service(serviceA, WithFn(func() any {
+			return &randomService{Name: "A"}
+		})),
+	).
+		MustBuild(context.TODO())
+	assert.NoError(t, ctn.Boot())
+
+	out := &methodInjectableService{}
+	assert.NoError(t, ctn.Inject(out))
+	assert.Same(t, ctn.MustGet(serviceA), out.a)
+	assert.Equal(t, "foo", out.name)
+	assert.Equal(t, 2, out.called)
+}
+
+type loggerService struct{}
+
+type loggerAwareService struct {
+	logger *loggerService
+}
+
+func (l *loggerAwareService) InjectLogger(logger *loggerService) {
+	l.logger = logger
+}
+
+func TestContainer_InjectMethodsSkipsFailing(t *testing.T) {
+	ctn := Builder(
+		Service("service.broken", WithErrorFn(func() (any, error) {
+			return nil, errors.New("boom")
+		})),
+		Service("service.logger", WithFn(func() any { return &loggerService{} })),
+	).
+		WithTypeProbing(true).
+		MustBuild(context.TODO())
+
+	out := &loggerAwareService{}
+	assert.NoError(t, ctn.Inject(out))
+	assert.Same(t, ctn.MustGet("service.logger"), out.logger)
+}
+
+func TestContainer_InjectMethodsLazy(t *testing.T) {
+	created := make([]string, 0)
+	ctn := Builder(
+		Service("service.mailer", WithFn(func() any {
+			created = append(created, "mailer")
+			return &randomService{}
+		})),
+		Service("service.logger", WithFn(func() any {
+			created = append(created, "logger")
+			return &loggerService{}
+		})),
+	).
+		MustBuild(context.TODO())
+
+	// services are not instantiated just to find out their type
+	err := ctn.Inject(&loggerAwareService{})
+	assert.ErrorIs(t, err, ErrUnknownService)
+	assert.ErrorContains(t, err, "either boot the container or enable type probing")
+	assert.Empty(t, created)
+
+	logger := ctn.MustGet("service.logger")
+	out := &loggerAwareService{}
+	assert.NoError(t, ctn.Inject(out))
+	assert.Same(t, logger, out.logger)
+	assert.Equal(t, []string{"logger"}, created)
+}
+
+func TestContainer_InjectMethodsPrefersInstantiated(t *testing.T) {
+	created := 0
+	ctn := Builder(
+		Service("service.lazy", WithFn(func() any {
+			created++
+			return &loggerService{}
+		})),
+		Service("service.logger", WithInstance(&loggerService{})),
+	).
+		MustBuild(context.TODO())
+
+	out := &loggerAwareService{}
+	assert.NoError(t, ctn.Inject(out))
+	assert.Same(t, ctn.MustGet("service.logger"), out.logger)
+	assert.Equal(t, 0, created)
+}
+
+type anyAwareService struct {
+	value any
+}
+
+func (a *anyAwareService) InjectValue(value any) {
+	a.value = value
+}
+
+func TestContainer_InjectMethodsAmbiguous(t *testing.T) {
+	ctn := Builder(
+		Service("service.logger", WithFn(func() any { return &loggerService{} })),
+		Service("service.other", WithFn(func() any { return &loggerService{} })),
+	).
+		WithTypeProbing(true).
+		MustBuild(context.TODO())
+
+	err := ctn.Inject(&loggerAwareService{})
+	assert.ErrorIs(t, err, ErrAmbiguousType)
+	assert.ErrorContains(t, err, `"service.logger", "service.other" are all assignable to type "*dimple.loggerService"`)
+
+	err = ctn.Inject(&anyAwareService{})
+	assert.ErrorIs(t, err, ErrAmbiguousType)
+}
+
+func TestContainer_InjectMethodsNoneFound(t *testing.T) {
+	ctn := Builder(
+		Service("service.broken", WithErrorFn(func() (any, error) {
+			return nil, errors.New("boom")
+		})),
+	).
+		WithTypeProbing(true).
+		MustBuild(context.TODO())
+
+	err := ctn.Inject(&loggerAwareService{})
+	assert.ErrorIs(t, err, ErrUnknownService)
+	assert.ErrorContains(t, err, `while "service.broken" failed to instantiate`)
+}
+
+type notInjectableService struct {
+	called []string
+}
+
+func (n *notInjectableService) Injected() bool {
+	n.called = append(n.called, "Injected")
+	return true
+}
+
+func (n *notInjectableService) Injector(_ *randomService) {
+	n.called = append(n.called, "Injector")
+}
+
+func (n *notInjectableService) InjectCount(_ string) int {
+	n.called = append(n.called, "InjectCount")
+	return 0
+}
+
+func TestContainer_InjectMethodsSkipped(t *testing.T) {
+	ctn := Builder(
+		Param("param.name", "foo"),
+		Service("service.a", WithInstance(&randomService{Name: "A"})),
+	).
+		MustBuild(context.TODO())
+
+	out := &notInjectableService{}
+	assert.NoError(t, ctn.Inject(out))
+	assert.Empty(t, out.called)
+}
+
+func TestContainer_InjectMethodsUnresolvable(t *testing.T) {
+	ctn := Builder().MustBuild(context.TODO())
+
+	err := ctn.Inject(&methodInjectableService{})
+	assert.ErrorIs(t, err, ErrUnknownService)
+	assert.Contains(t, err.Error(), `InjectA`)
+}
+
+type closableService struct {
+	name   string
+	closed *[]string
+	err    error
+}
+
+func (c *closableService) Close() error {
+	*c.closed = append(*c.closed, c.name)
+	return c.err
+}
+
+type shutdownService struct {
+	closableService
+}
+
+func (s *shutdownService) Shutdown(_ context.Context) error {
+	return s.Close()
+}
+
+func TestContainer_Shutdown(t *testing.T) {
+	closed := make([]string, 0)
+
+	ctn := Builder(
+		Service("service.a", WithFn(func() any {
+			return &closableService{name: "A", closed: &closed}
+		})),
+		Service("service.b", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			_ = ctx.Container().MustGet("service.a")
+
+			return &shutdownService{closableService{name: "B", closed: &closed, err: errors.New("boom")}}, nil
+		})),
+		Decorator("decorator.b", "service.b", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return ctx.Decorated(), nil
+		})),
+		Service("service.c", WithFn(func() any {
+			return &closableService{name: "C", closed: &closed}
+		})),
+		Service("service.d", WithInstance(&closableService{name: "D", closed: &closed})),
+	).
+		MustBuild(context.TODO())
+
+	_ = ctn.MustGet("service.d")
+	_ = ctn.MustGet("service.b")
+
+	err := ctn.Shutdown(context.TODO())
+	assert.ErrorContains(t, err, `cannot shutdown service "service.b": boom`)
+	assert.Equal(t, []string{"B", "A"}, closed)
+
+	// nothing left to close
+	assert.NoError(t, ctn.Shutdown(context.TODO()))
+	assert.Equal(t, []string{"B", "A"}, closed)
+}
+
+func TestContainer_Override(t *testing.T) {
+	ctn := Builder(
+		Service("service.a", WithFn(func() any { return &randomService{Name: "A"} })),
+		Decorator("decorator.a", "service.a", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return &randomService{Name: "decorated"}, nil
+		})),
+	).
+		MustBuild(context.TODO())
+
+	assert.Equal(t, "decorated", ctn.MustGet("service.a").(*randomService).Name)
+
+	ctn.Override(Service("service.a", WithFn(func() any { return &randomService{Name: "mock"} })))
+	assert.Equal(t, "mock", ctn.MustGet("service.a").(*randomService).Name)
+}
+
+type traceKey struct{}
+
+func TestContainer_GetCtx(t *testing.T) {
+	ctn := Builder(
+		Service("service.a", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return ctx.Container().Get("service.b")
+		})),
+		Service("service.b", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return &randomService{Name: ctx.Value(traceKey{}).(string)}, nil
+		})),
+	).
+		MustBuild(context.TODO())
+
+	ctx := context.WithValue(context.TODO(), traceKey{}, "trace-1")
+	a, err := ctn.GetCtx(ctx, "service.a")
+	assert.NoError(t, err)
+	assert.Equal(t, "trace-1", a.(*randomService).Name)
+
+	// already instantiated
+	ctx = context.WithValue(context.TODO(), traceKey{}, "trace-2")
+	assert.Same(t, a, MustGetT[*randomService](ctn, "service.b"))
+	b, err := ctn.GetCtx(ctx, "service.b")
+	assert.NoError(t, err)
+	assert.Same(t, a, b)
+}
+
+func TestContainer_GetCtxCancelled(t *testing.T) {
+	release := make(chan struct{})
+	calls := 0
+
+	ctn := Builder(
+		Service("service.a", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return ctx.Container().Get("service.slow")
+		})),
+		Service("service.slow", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			calls++
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-release:
+				return &randomService{}, nil
+			}
+		})),
+	).
+		MustBuild(context.TODO())
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	_, err := ctn.GetCtx(ctx, "service.a")
+	assert.ErrorIs(t, err, ErrResolutionCancelled)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, calls)
+
+	ctx, cancel = context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = ctn.GetCtx(ctx, "service.a")
+	assert.ErrorIs(t, err, ErrResolutionCancelled)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, calls)
+
+	// the cancelled resolution did not leave any instance behind
+	close(release)
+	_, err = ctn.GetCtx(context.TODO(), "service.a")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestContainer_GetAfterBuildCtxCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	ctn := Builder(
+		Service("service.a", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return ctx.Container().Get("service.b")
+		})),
+		Service("service.b", WithErrorFn(func() (any, error) {
+			return &randomService{}, nil
+		})).WithTimeout(time.Second).WithRetry(2, 0),
+	).
+		MustBuild(ctx)
+
+	cancel()
+
+	_, err := ctn.Get("service.a")
+	assert.NoError(t, err)
+}
+
+func TestContainer_GetCtxCancelledWhileWaiting(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	ctn := Builder(
+		Service("service.slow", WithFn(func() any {
+			close(started)
+			<-release
+
+			return &randomService{}
+		})),
+	).
+		MustBuild(context.TODO())
+
+	done := make(chan any)
+	go func() {
+		done <- ctn.MustGet("service.slow")
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := ctn.GetCtx(ctx, "service.slow")
+	assert.ErrorIs(t, err, ErrResolutionCancelled)
+
+	close(release)
+	assert.Same(t, <-done, ctn.MustGet("service.slow"))
+}
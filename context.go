@@ -2,11 +2,48 @@ package dimple
 
 import (
 	"context"
+	"fmt"
 	"time"
 )
 
 var _ FactoryCtx = (*factoryContext)(nil)
 
+type containerKey struct{}
+
+// WithContainer returns a copy of ctx carrying the given container
+func WithContainer(ctx context.Context, c Container) context.Context {
+	return context.WithValue(ctx, containerKey{}, c)
+}
+
+// FromContext returns the container carried by ctx or nil if there is none
+func FromContext(ctx context.Context) Container {
+	c, _ := ctx.Value(containerKey{}).(Container)
+
+	return c
+}
+
+// GetFromCtx resolves the service from the container carried by ctx. The resolution is bound to ctx.
+func GetFromCtx[T any](ctx context.Context, id string) (T, error) {
+	var zero T
+
+	c := FromContext(ctx)
+	if c == nil {
+		return zero, fmt.Errorf(`%w: cannot resolve service "%s"`, ErrNoContainer, id)
+	}
+
+	val, err := c.GetCtx(ctx, id)
+	if err != nil {
+		return zero, err
+	}
+
+	valT, ok := val.(T)
+	if !ok {
+		return zero, fmt.Errorf(`illegal type assertion for service "%s" of type "%T"`, id, val)
+	}
+
+	return valT, nil
+}
+
 func newFactoryCtx(ctx context.Context, c *DefaultContainer, d any) FactoryCtx {
 	return &factoryContext{
 		ctx:       ctx,
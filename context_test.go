@@ -0,0 +1,71 @@
+// nolint
+package dimple
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContext(t *testing.T) {
+	assert.Nil(t, FromContext(context.TODO()))
+
+	ctn := Builder(Param("param.name", "A")).MustBuild(context.TODO())
+	assert.Same(t, ctn, FromContext(ctn.Ctx()))
+	assert.Same(t, ctn, FromContext(MustGetT[context.Context](ctn, "context")))
+
+	other := Builder().MustBuild(context.TODO())
+	assert.Same(t, other, FromContext(WithContainer(ctn.Ctx(), other)))
+
+	fork := ctn.Fork()
+	assert.Same(t, fork, FromContext(fork.Ctx()))
+	assert.Same(t, fork, FromContext(MustGetT[context.Context](fork, "context")))
+	assert.Same(t, ctn, FromContext(ctn.Ctx()))
+}
+
+func TestGetFromCtx(t *testing.T) {
+	type ctxKey struct{}
+
+	ctn := Builder(
+		Param("param.name", "A"),
+		Service("service.a", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			name, err := GetFromCtx[string](ctx, "param.name")
+			if err != nil {
+				return nil, err
+			}
+
+			return &randomService{Name: name + ctx.Value(ctxKey{}).(string)}, nil
+		})),
+	).
+		MustBuild(context.TODO())
+
+	ctx := WithContainer(context.WithValue(context.TODO(), ctxKey{}, "B"), ctn)
+	svc, err := GetFromCtx[*randomService](ctx, "service.a")
+	assert.NoError(t, err)
+	assert.Equal(t, "AB", svc.Name)
+
+	_, err = GetFromCtx[int](ctx, "param.name")
+	assert.ErrorContains(t, err, `illegal type assertion for service "param.name" of type "string"`)
+
+	_, err = GetFromCtx[string](ctx, "unknown")
+	assert.ErrorIs(t, err, ErrUnknownService)
+
+	_, err = GetFromCtx[string](context.TODO(), "param.name")
+	assert.ErrorIs(t, err, ErrNoContainer)
+}
+
+func TestGetCtx_AttachesContainer(t *testing.T) {
+	var got Container
+	ctn := Builder(
+		Service("service.a", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			got = FromContext(ctx)
+			return &randomService{}, nil
+		})),
+	).
+		MustBuild(context.TODO())
+
+	_, err := ctn.GetCtx(context.Background(), "service.a")
+	assert.NoError(t, err)
+	assert.Same(t, ctn, got)
+}
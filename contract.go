@@ -1,6 +1,9 @@
 package dimple
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // ContainerBuilder abstraction interface
 type ContainerBuilder interface {
@@ -8,13 +11,23 @@ type ContainerBuilder interface {
 	// - ServiceDef for a service
 	// - DecoratorDef if you want to decorate another service
 	// - ParamDef any parameter value of any type
+	// If a Definition of the same ID has already been added it will be rejected and Build() returns an error
+	// naming both registrations.
 	Add(def Definition) ContainerBuilder
 
+	// Override adds the Definition and replaces an existing Definition of the same ID if there is any
+	Override(def Definition) ContainerBuilder
+
 	// Get returns a Definition by its ID, otherwise nil if it does not exist
 	Get(id string) Definition
 
 	// Has returns TRUE if a Definition of given ID exists
 	Has(id string) bool
+
+	// Install adds the definitions of the given modules to the ContainerBuilder. Modules are installed in
+	// order of their declared dependencies and their Configure hook is called afterwards. It returns an error
+	// if a module has already been installed, a dependency is missing or a definition ID is already in use.
+	Install(modules ...Module) error
 }
 
 // Container abstraction interface
@@ -22,9 +35,16 @@ type Container interface {
 	// Has will return TRUE when a service or param by given id exist, otherwise FALSE
 	Has(id string) bool
 
-	// Get will return a plain value (for ParamDef) or the instance (for ServiceDef and DecoratorDef) by id
+	// Get will return a plain value (for ParamDef) or the instance (for ServiceDef and DecoratorDef) by id.
+	// When called from within a factory of a namespaced definition the id might be relative to the namespace,
+	// which takes precedence over a global definition of the same ID.
+	// It returns ErrPrivateService if the definition is private and not requested from the same namespace.
 	Get(id string) (any, error)
 
+	// GetCtx will return the value or instance by id like Get() but the given context will be passed to the factories
+	// of all services instantiated by this call. It returns ErrResolutionCancelled once the context is done.
+	GetCtx(ctx context.Context, id string) (any, error)
+
 	// MustGet will return the param value or service instance by id
 	// Beware that this can panic at runtime if any instantiation errors occur!
 	// Consider to explicitly call Boot() before using it
@@ -37,22 +57,95 @@ type Container interface {
 	//     TimeService     *TimeService   `inject:"service.time"`
 	//     TimeFormat      string         `inject:"param.time_format"`
 	// }
+	//
+	// Afterwards every exported method named "Inject" followed by an upper-case letter (e.g. InjectLogger(l *slog.Logger))
+	// returning either nothing or only an error will be called.
+	// Its arguments are resolved by type using the only definition whose value is assignable. Only parameters and
+	// services which have been instantiated already are considered, unless type probing has been enabled on the
+	// builder. It returns ErrAmbiguousType if more than one definition matches.
 	Inject(target any) error
 
 	// Boot will instantiate all services eagerly. It is not mandatory to call Boot() since all
-	// services (except decorated services) will be instantiated lazy per default.
+	// services (including decorated services) will be instantiated lazy per default.
 	// Beware that lazy instantiation can cause a panic at runtime when retrieving values via MustGet()!
 	// If you want to ensure that every service can be instantiated properly it is recommended to call Boot()
 	// before first use of MustGet().
 	Boot() error
 
+	// BootParallel will instantiate all services eagerly like Boot() but independent services are instantiated
+	// concurrently by the given number of workers. Services will be instantiated after the services they are known
+	// to depend on. Booting stops on cancellation of the context. All errors which occurred are returned.
+	BootParallel(ctx context.Context, workers int) error
+
+	// Compile freezes the definitions, so neither the container nor its builder can override them anymore, and
+	// serves services which have been instantiated already without locking. Services will be booted in order of
+	// their dependencies.
+	Compile() error
+
 	// Ctx returns the context.Context
 	Ctx() context.Context
+
+	// DecoratorChain returns the IDs of all decorators of the given service in order of application,
+	// starting with the decorator wrapping the origin service
+	DecoratorChain(id string) []string
+
+	// Tagged returns the IDs of all services carrying the given tag in order of registration
+	Tagged(tag string) []string
+
+	// Tags returns the tags of the service by given id
+	Tags(id string) []Tag
+
+	// Health runs the health checks of all instantiated services implementing HealthChecker and services tagged
+	// by HealthTag concurrently. Each check is limited by a timeout.
+	Health(ctx context.Context) HealthReport
+
+	// Shutdown closes all instantiated services in reverse order of their instantiation. Services are closed
+	// if they implement either Shutdown(ctx context.Context) error, io.Closer or Close(). Instances passed in by
+	// WithInstance are owned by the caller and never closed.
+	Shutdown(ctx context.Context) error
+
+	// Override replaces the definition of the same ID even if it has been instantiated or decorated already.
+	// Services which have been instantiated before keep the instance they depend on.
+	Override(def Definition)
+
+	// SetParam replaces the value of the parameter by given id. Services depending on it, directly or transitively,
+	// are closed and instantiated again on next use or right away if the container has been booted.
+	// Afterwards the callbacks registered by Watch() are invoked.
+	SetParam(id string, v any) error
+
+	// Reset closes the instance of the service by given id along with its decorators and drops it, so the service
+	// will be instantiated again on next use. With cascade all services depending on it, directly or transitively,
+	// are reset as well. Otherwise they keep the instance they depend on. Instances passed in by WithInstance are
+	// not closed but injected again on next use.
+	Reset(id string, cascade bool) error
+
+	// Watch registers a callback which is invoked whenever the value of the parameter by given id has been
+	// changed by SetParam(). It returns a function to remove the callback. Callbacks must not call SetParam().
+	Watch(id string, fn func(old, new any)) func()
+
+	// Fork returns a copy of the container sharing its definitions and instantiated services. Definitions can be
+	// overridden in the fork without affecting the original container and vice versa.
+	Fork() Container
+
+	// ForkFresh returns a copy of the container like Fork() but services will be instantiated again within the fork.
+	ForkFresh() Container
+
+	// Scope returns a child container which instantiates only services carrying the given tag and definitions
+	// overridden within the scope. All other services are resolved from this container, so they are shared
+	// across scopes. Shutdown() of the scope closes the services instantiated within the scope only. Each scope
+	// holds a copy of the definitions, so opening one takes time linear to the number of definitions.
+	Scope(tag string) Container
 }
 
 // Definition abstraction interface
 type Definition interface {
 	Id() string
+	// Namespace returns the namespace of the Module which registered the definition
+	Namespace() string
+	// Private returns TRUE if the definition can only be injected into definitions of the same namespace
+	Private() bool
+	// Source returns the file and line where the definition has been declared if known
+	Source() string
 }
 
 // ParamDef abstraction interface
@@ -60,6 +153,10 @@ type ParamDef interface {
 	Definition
 	Value() any
 	WithID(id string) ParamDef
+	WithNamespace(namespace string) ParamDef
+	// WithPrivate hides the definition from Container.Get(). It can only be injected into definitions
+	// of the same namespace.
+	WithPrivate() ParamDef
 	WithValue(v any) ParamDef
 }
 
@@ -68,9 +165,28 @@ type ServiceDef interface {
 	Definition
 	Factory() Factory
 	Instance() any
+	Calls() []Call
+	Tags() []Tag
+	Timeout() time.Duration
+	Retry() Retry
 	WithID(id string) ServiceDef
+	WithNamespace(namespace string) ServiceDef
+	// WithPrivate hides the definition from Container.Get(). It can only be injected into definitions
+	// of the same namespace.
+	WithPrivate() ServiceDef
 	WithFactory(factory Factory) ServiceDef
 	WithInstance(instance any) ServiceDef
+	// WithCall registers a method to be called on the instance after field injection. The args are
+	// IDs of services or params passed to the method in the given order.
+	WithCall(method string, args ...string) ServiceDef
+	// WithTag attaches a tag to the service. The attributes are expected as alternating keys and values.
+	WithTag(name string, attributes ...string) ServiceDef
+	// WithTimeout limits each attempt of the factory. Context aware factories get a deadline of the FactoryCtx,
+	// while a FactoryFnWithError is abandoned once the deadline has passed. Other factories are not limited.
+	WithTimeout(timeout time.Duration) ServiceDef
+	// WithRetry calls the factory up to the given number of attempts until it succeeds. The backoff is the
+	// delay before the second attempt and doubles for each further attempt.
+	WithRetry(attempts int, backoff time.Duration) ServiceDef
 }
 
 // DecoratorDef abstraction interface
@@ -80,11 +196,37 @@ type DecoratorDef interface {
 	Instance() any
 	Decorates() string
 	Decorated() Definition
+	Priority() int
+	DecoratesTag() string
+	Condition() func(ctx FactoryCtx) bool
+	Timeout() time.Duration
+	Retry() Retry
 	WithID(id string) DecoratorDef
+	WithNamespace(namespace string) DecoratorDef
+	// WithPrivate hides the definition from Container.Get(). It can only be injected into definitions
+	// of the same namespace.
+	WithPrivate() DecoratorDef
 	WithFactory(factory Factory) DecoratorDef
 	WithInstance(instance any) DecoratorDef
 	WithDecorates(id string) DecoratorDef
 	WithDecorated(def Definition) DecoratorDef
+	// WithPriority sets the priority of the decorator. Decorators with a higher priority are applied first,
+	// which means they are closer to the decorated service. Decorators of equal priority are applied
+	// in order of registration.
+	WithPriority(priority int) DecoratorDef
+	// WithDecoratesTag makes the decorator decorate every service carrying the given tag
+	WithDecoratesTag(tag string) DecoratorDef
+	// When adds a condition which is evaluated on instantiation of the decorator. If it does not hold the
+	// decorated service will be used as it is. Multiple conditions must all hold.
+	When(condition func(ctx FactoryCtx) bool) DecoratorDef
+	// WhenParam adds a condition which holds if the param by given ID equals v
+	WhenParam(id string, v any) DecoratorDef
+	// WithTimeout limits each attempt of the factory. Context aware factories get a deadline of the FactoryCtx,
+	// while a FactoryFnWithError is abandoned once the deadline has passed. Other factories are not limited.
+	WithTimeout(timeout time.Duration) DecoratorDef
+	// WithRetry calls the factory up to the given number of attempts until it succeeds. The backoff is the
+	// delay before the second attempt and doubles for each further attempt.
+	WithRetry(attempts int, backoff time.Duration) DecoratorDef
 }
 
 type FactoryCtx interface {
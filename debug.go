@@ -0,0 +1,119 @@
+package dimple
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// DefinitionInfo describes a registered definition for debugging purposes
+type DefinitionInfo struct {
+	ID           string
+	Kind         string
+	Namespace    string
+	Private      bool
+	Instantiated bool
+	Source       string
+}
+
+// Visibility returns either "private" or "public"
+func (i DefinitionInfo) Visibility() string {
+	if i.Private {
+		return "private"
+	}
+
+	return "public"
+}
+
+// Definitions returns information about all registered definitions in order of registration
+func (c *DefaultContainer) Definitions() []DefinitionInfo {
+	infos := make([]DefinitionInfo, 0)
+	for _, id := range c.getOrder() {
+		def := c.lookup(id)
+		if def == nil {
+			continue
+		}
+
+		info := DefinitionInfo{
+			ID:        id,
+			Namespace: def.Namespace(),
+			Private:   def.Private(),
+			Source:    def.Source(),
+		}
+
+		switch t := def.(type) {
+		case ParamDef:
+			info.Kind = "param"
+			info.Instantiated = true
+		case ServiceDef:
+			info.Kind = "service"
+			info.Instantiated = t.Instance() != nil
+			if alias, ok := c.getDefinition(id).(DecoratorDef); ok {
+				// a decorated service is instantiated once its outermost decorator is
+				outermost, ok := c.getDefinition(alias.Id()).(DecoratorDef)
+				info.Instantiated = ok && outermost.Instance() != nil
+			}
+		case DecoratorDef:
+			info.Kind = "decorator"
+			info.Instantiated = t.Instance() != nil
+		}
+
+		infos = append(infos, info)
+	}
+
+	return infos
+}
+
+// Debug writes a listing of all registered definitions to w
+func (c *DefaultContainer) Debug(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "ID\tKIND\tVISIBILITY\tNAMESPACE\tINSTANTIATED\tSOURCE"); err != nil {
+		return err
+	}
+
+	for _, info := range c.Definitions() {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%t\t%s\n", info.ID, info.Kind, info.Visibility(), info.Namespace, info.Instantiated, info.Source); err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
+
+// Graph writes the definitions and their dependencies in DOT format to w. Dependencies of services are only
+// known once they have been instantiated, so you might want to call Boot() beforehand.
+func (c *DefaultContainer) Graph(w io.Writer) error {
+	lines := []string{"digraph dimple {"}
+	for _, info := range c.Definitions() {
+		label := fmt.Sprintf("%s\n%s %s", info.ID, info.Visibility(), info.Kind)
+		if info.Source != "" {
+			label = fmt.Sprintf("%s\n%s", label, info.Source)
+		}
+
+		lines = append(lines, fmt.Sprintf(`  %q [label=%q];`, info.ID, label))
+	}
+
+	for _, id := range c.getOrder() {
+		decorates := ""
+		if dec, ok := c.getDefinition(id).(DecoratorDef); ok && dec.Id() == id {
+			decorates = dec.Decorates()
+			if inner, ok := dec.Decorated().(DecoratorDef); ok {
+				decorates = inner.Id()
+			}
+
+			lines = append(lines, fmt.Sprintf(`  %q -> %q [style=dashed, label="decorates"];`, id, decorates))
+		}
+
+		for _, dependency := range c.getDependencies(id) {
+			if dependency != decorates {
+				lines = append(lines, fmt.Sprintf(`  %q -> %q;`, id, dependency))
+			}
+		}
+	}
+
+	lines = append(lines, "}")
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+
+	return err
+}
@@ -0,0 +1,146 @@
+// nolint
+package dimple
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultContainer_Definitions(t *testing.T) {
+	b := Builder(Param("param.a", "A"))
+	assert.NoError(t, b.Install(Module{
+		Name:      "auth",
+		Namespace: "auth",
+		Definitions: []Definition{
+			Service("token_store", WithInstance(&randomService{})).WithPrivate(),
+			Service("service", WithContextFn(func(ctx FactoryCtx) (any, error) {
+				_, err := ctx.Container().Get("token_store")
+
+				return &randomService{}, err
+			})),
+			Decorator("decorator", "service", WithFn(func() any { return &randomService{} })),
+		},
+	}))
+
+	c := b.MustBuild(context.TODO())
+	assert.NoError(t, c.Boot())
+
+	infos := c.Definitions()
+	for i := range infos {
+		if infos[i].Source != "" {
+			assert.Regexp(t, `/debug_test.go:\d+$`, infos[i].Source)
+			infos[i].Source = ""
+		}
+	}
+
+	assert.Equal(t, []DefinitionInfo{
+		{ID: "container", Kind: "service", Instantiated: true},
+		{ID: "param.a", Kind: "param", Instantiated: true},
+		{ID: "auth.token_store", Kind: "service", Namespace: "auth", Private: true, Instantiated: true},
+		{ID: "auth.service", Kind: "service", Namespace: "auth", Instantiated: true},
+		{ID: "auth.decorator", Kind: "decorator", Namespace: "auth", Instantiated: true},
+		{ID: "context", Kind: "service", Instantiated: true},
+	}, infos)
+}
+
+func TestDefaultContainer_Debug(t *testing.T) {
+	b := Builder(Param("param.a", "A")).WithSourceCapture(false)
+	assert.NoError(t, b.Install(Module{
+		Name:      "auth",
+		Namespace: "auth",
+		Definitions: []Definition{
+			Service("token_store", WithInstance(&randomService{})).WithPrivate(),
+			Service("service", WithContextFn(func(ctx FactoryCtx) (any, error) {
+				_, err := ctx.Container().Get("token_store")
+
+				return &randomService{}, err
+			})),
+			Decorator("decorator", "service", WithFn(func() any { return &randomService{} })),
+		},
+	}))
+
+	c := b.MustBuild(context.TODO())
+	assert.NoError(t, c.Boot())
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, c.Debug(out))
+	assert.Equal(t, `ID                KIND       VISIBILITY  NAMESPACE  INSTANTIATED  SOURCE
+container         service    public                 true          
+param.a           param      public                 true          
+auth.token_store  service    private     auth       true          
+auth.service      service    public      auth       true          
+auth.decorator    decorator  public      auth       true          
+context           service    public                 true          
+`, out.String())
+}
+
+func TestDefaultContainer_Graph(t *testing.T) {
+	b := Builder(Param("param.a", "A")).WithSourceCapture(false)
+	assert.NoError(t, b.Install(Module{
+		Name:      "auth",
+		Namespace: "auth",
+		Definitions: []Definition{
+			Service("token_store", WithInstance(&randomService{})).WithPrivate(),
+			Service("service", WithContextFn(func(ctx FactoryCtx) (any, error) {
+				_, err := ctx.Container().Get("token_store")
+
+				return &randomService{}, err
+			})),
+			Decorator("decorator", "service", WithFn(func() any { return &randomService{} })),
+		},
+	}))
+
+	c := b.MustBuild(context.TODO())
+	assert.NoError(t, c.Boot())
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, c.Graph(out))
+	assert.Equal(t, `digraph dimple {
+  "container" [label="container\npublic service"];
+  "param.a" [label="param.a\npublic param"];
+  "auth.token_store" [label="auth.token_store\nprivate service"];
+  "auth.service" [label="auth.service\npublic service"];
+  "auth.decorator" [label="auth.decorator\npublic decorator"];
+  "context" [label="context\npublic service"];
+  "auth.service" -> "auth.token_store";
+  "auth.decorator" -> "auth.service" [style=dashed, label="decorates"];
+}
+`, out.String())
+}
+
+func TestSourceCapture(t *testing.T) {
+	_, err := Builder(
+		Service("service.a", WithErrorFn(func() (any, error) {
+			return nil, assert.AnError
+		})),
+	).MustBuild(context.TODO()).Get("service.a")
+
+	assert.ErrorIs(t, err, ErrServiceFactoryFailed)
+	assert.Regexp(t, `declared at .*/debug_test.go:\d+$`, err.Error())
+
+	b := Builder(Param("param.a", "A")).WithSourceCapture(false)
+	b.Add(Param("param.a", "B"))
+	_, err = b.Build(context.TODO())
+	assert.ErrorContains(t, err, `"param.a" registered at <unknown> has already been registered at <unknown>`)
+
+	// definitions passed to Builder() are declared before the option is set
+	c := Builder(
+		Service("service.a", WithErrorFn(func() (any, error) {
+			return nil, assert.AnError
+		})),
+	).WithSourceCapture(false).MustBuild(context.TODO())
+	assert.Empty(t, c.lookup("service.a").Source())
+
+	_, err = c.Get("service.a")
+	assert.NotContains(t, err.Error(), "declared at")
+}
+
+func BenchmarkService(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = Service("service.a", WithInstance(nil))
+	}
+}
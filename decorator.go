@@ -1,24 +1,48 @@
 package dimple
 
+import (
+	"reflect"
+	"time"
+)
+
 var _ DecoratorDef = (*decoratorDef)(nil)
 
 // Decorator returns a new instance of DecoratorDef
 func Decorator(id string, decorates string, factory Factory) DecoratorDef {
 	return &decoratorDef{
 		definition: definition{
-			id: id,
+			id:     id,
+			source: captureSource(2),
 		},
 		factory:   factory,
 		decorates: decorates,
 	}
 }
 
+// DecorateTagged returns a new instance of DecoratorDef which decorates every service carrying the given tag.
+// For each of those services a decorator will be registered by the ID "<id>.<service id>".
+func DecorateTagged(id string, tag string, factory Factory) DecoratorDef {
+	return &decoratorDef{
+		definition: definition{
+			id:     id,
+			source: captureSource(2),
+		},
+		factory:      factory,
+		decoratesTag: tag,
+	}
+}
+
 type decoratorDef struct {
 	definition
-	factory   Factory
-	instance  any
-	decorates string
-	decorated Definition
+	factory      Factory
+	instance     any
+	decorates    string
+	decorated    Definition
+	priority     int
+	decoratesTag string
+	condition    func(ctx FactoryCtx) bool
+	timeout      time.Duration
+	retry        Retry
 }
 
 func (d *decoratorDef) Decorated() Definition {
@@ -37,6 +61,32 @@ func (d *decoratorDef) Decorates() string {
 	return d.decorates
 }
 
+func (d *decoratorDef) DecoratesTag() string {
+	return d.decoratesTag
+}
+
+func (d *decoratorDef) Condition() func(ctx FactoryCtx) bool {
+	return d.condition
+}
+
+func (d *decoratorDef) Priority() int {
+	return d.priority
+}
+
+func (d *decoratorDef) WithNamespace(namespace string) DecoratorDef {
+	c := d.clone()
+	c.namespace = namespace
+
+	return c
+}
+
+func (d *decoratorDef) WithPrivate() DecoratorDef {
+	c := d.clone()
+	c.private = true
+
+	return c
+}
+
 func (d *decoratorDef) WithID(id string) DecoratorDef {
 	c := d.clone()
 	c.id = id
@@ -72,12 +122,76 @@ func (d *decoratorDef) WithDecorated(def Definition) DecoratorDef {
 	return c
 }
 
+func (d *decoratorDef) WithPriority(priority int) DecoratorDef {
+	c := d.clone()
+	c.priority = priority
+
+	return c
+}
+
+func (d *decoratorDef) WithDecoratesTag(tag string) DecoratorDef {
+	c := d.clone()
+	c.decoratesTag = tag
+
+	return c
+}
+
+func (d *decoratorDef) When(condition func(ctx FactoryCtx) bool) DecoratorDef {
+	c := d.clone()
+	if prev := d.condition; prev != nil {
+		c.condition = func(ctx FactoryCtx) bool {
+			return prev(ctx) && condition(ctx)
+		}
+
+		return c
+	}
+
+	c.condition = condition
+
+	return c
+}
+
+func (d *decoratorDef) WhenParam(id string, v any) DecoratorDef {
+	return d.When(func(ctx FactoryCtx) bool {
+		val, err := ctx.Container().Get(id)
+
+		return err == nil && reflect.DeepEqual(val, v)
+	})
+}
+
+func (d *decoratorDef) WithTimeout(timeout time.Duration) DecoratorDef {
+	c := d.clone()
+	c.timeout = timeout
+
+	return c
+}
+
+func (d *decoratorDef) WithRetry(attempts int, backoff time.Duration) DecoratorDef {
+	c := d.clone()
+	c.retry = Retry{Attempts: attempts, Backoff: backoff}
+
+	return c
+}
+
+func (d *decoratorDef) Timeout() time.Duration {
+	return d.timeout
+}
+
+func (d *decoratorDef) Retry() Retry {
+	return d.retry
+}
+
 func (d *decoratorDef) clone() *decoratorDef {
 	return &decoratorDef{
-		definition: *d.definition.clone(),
-		factory:    d.Factory(),
-		instance:   d.Instance(),
-		decorates:  d.Decorates(),
-		decorated:  d.Decorated(),
+		definition:   *d.definition.clone(),
+		factory:      d.Factory(),
+		instance:     d.Instance(),
+		decorates:    d.Decorates(),
+		decorated:    d.Decorated(),
+		priority:     d.Priority(),
+		decoratesTag: d.DecoratesTag(),
+		condition:    d.Condition(),
+		timeout:      d.Timeout(),
+		retry:        d.Retry(),
 	}
 }
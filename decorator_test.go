@@ -171,3 +171,188 @@ func TestDecoratorWithError(t *testing.T) {
 	assert.IsType(t, &decoratorService{}, c)
 	assert.IsType(t, &decoratorDef{}, builder.Get(serviceA))
 }
+
+func TestDecoratorWithPriority(t *testing.T) {
+	const serviceA = "service.a"
+	const serviceB = "service.b"
+	const serviceC = "service.c"
+	const serviceD = "service.d"
+
+	origin := &randomService{Name: "A"}
+	decorate := func(name string) Factory {
+		return WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return &decoratorService{
+				randomService: randomService{Name: name},
+				Decorated:     ctx.Decorated().(randomInterface),
+			}, nil
+		})
+	}
+
+	var innermost any
+	builder := Builder(
+		Decorator(serviceD, serviceA, decorate("D")).WithPriority(-10),
+		Decorator(serviceB, serviceA, WithContextFn(func(ctx FactoryCtx) (any, error) {
+			innermost = ctx.Decorated()
+
+			return decorate("B").FactoryFnWithContext()(ctx)
+		})).WithPriority(10),
+		Service(serviceA, WithInstance(origin)),
+		Decorator(serviceC, serviceA, decorate("C")),
+	)
+
+	container := builder.MustBuild(context.TODO())
+	assert.Equal(t, []string{serviceB, serviceC, serviceD}, container.DecoratorChain(serviceA))
+	assert.Empty(t, container.DecoratorChain(serviceB))
+
+	assert.Equal(t, "ABCD", container.MustGet(serviceA).(randomInterface).SayMyName())
+	assert.Same(t, origin, innermost)
+	assert.Same(t, container.MustGet(serviceD), container.MustGet(serviceA))
+	assert.Equal(t, "AB", container.MustGet(serviceB).(randomInterface).SayMyName())
+	assert.Equal(t, "ABC", container.MustGet(serviceC).(randomInterface).SayMyName())
+}
+
+func TestDecoratorLazy(t *testing.T) {
+	const serviceA = "service.a"
+	const serviceB = "service.b"
+	const serviceC = "service.c"
+
+	calls := make([]string, 0)
+	builder := Builder(
+		Service(serviceA, WithFn(func() any {
+			calls = append(calls, serviceA)
+
+			return &randomService{Name: "A"}
+		})),
+		Decorator(serviceB, serviceA, WithContextFn(func(ctx FactoryCtx) (any, error) {
+			calls = append(calls, serviceB)
+
+			return &decoratorService{
+				randomService: randomService{Name: "B"},
+				Decorated:     ctx.Decorated().(randomInterface),
+			}, nil
+		})),
+		Decorator(serviceC, serviceA, WithContextFn(func(ctx FactoryCtx) (any, error) {
+			calls = append(calls, serviceC)
+			assert.Equal(t, serviceC, ctx.ServiceID())
+
+			return &decoratorService{
+				randomService: randomService{Name: "C"},
+				Decorated:     ctx.Decorated().(randomInterface),
+			}, nil
+		})),
+	)
+
+	container := builder.MustBuild(context.TODO())
+	assert.Empty(t, calls)
+
+	b := container.MustGet(serviceB)
+	assert.Equal(t, []string{serviceA, serviceB}, calls)
+	assert.Equal(t, "AB", b.(randomInterface).SayMyName())
+
+	a := container.MustGet(serviceA)
+	assert.Equal(t, []string{serviceA, serviceB, serviceC}, calls)
+	assert.Equal(t, "ABC", a.(randomInterface).SayMyName())
+	assert.Same(t, container.MustGet(serviceC), a)
+	assert.Same(t, b, a.(*decoratorService).Decorated)
+
+	assert.NoError(t, container.Boot())
+	assert.Equal(t, []string{serviceA, serviceB, serviceC}, calls)
+}
+
+func TestDecoratorCircularDependency(t *testing.T) {
+	const serviceA = "service.a"
+	const serviceB = "service.b"
+
+	container := Builder(
+		Service(serviceA, WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return ctx.Container().Get(serviceA)
+		})),
+		Decorator(serviceB, serviceA, WithFn(func() any {
+			return &randomService{Name: "B"}
+		})),
+	).MustBuild(context.TODO())
+
+	_, err := container.Get(serviceA)
+	assert.ErrorContains(t, err, ErrCircularDependency.Error())
+	assert.ErrorContains(t, err, `"service.b":decorates("service.a") -> "service.a" -> "service.a"`)
+}
+
+func TestDecoratorUnknownService(t *testing.T) {
+	_, err := Builder(
+		Decorator("service.b", "service.a", WithFn(func() any {
+			return &randomService{Name: "B"}
+		})),
+	).Build(context.TODO())
+
+	assert.ErrorIs(t, err, ErrUnknownService)
+}
+
+func TestDecorateTagged(t *testing.T) {
+	const serviceA = "service.a"
+	const serviceB = "service.b"
+	const serviceC = "service.c"
+	const decorator = "decorator"
+
+	decorate := func(name string) Factory {
+		return WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return &decoratorService{
+				randomService: randomService{Name: name},
+				Decorated:     ctx.Decorated().(randomInterface),
+			}, nil
+		})
+	}
+
+	container := Builder(
+		Service(serviceA, WithInstance(&randomService{Name: "A"})).WithTag("tagged"),
+		Service(serviceB, WithInstance(&randomService{Name: "B"})),
+		Service(serviceC, WithInstance(&randomService{Name: "C"})).WithTag("tagged"),
+		Decorator("decorator.c", serviceC, decorate("Y")).WithPriority(-1),
+		DecorateTagged(decorator, "tagged", decorate("X")),
+	).MustBuild(context.TODO())
+
+	assert.NoError(t, container.Boot())
+	assert.Equal(t, []string{serviceA, serviceC}, container.Tagged("tagged"))
+	assert.Equal(t, []string{"decorator.service.a"}, container.DecoratorChain(serviceA))
+	assert.Equal(t, []string{"decorator.service.c", "decorator.c"}, container.DecoratorChain(serviceC))
+
+	assert.Equal(t, "AX", container.MustGet(serviceA).(randomInterface).SayMyName())
+	assert.Equal(t, "B", container.MustGet(serviceB).(randomInterface).SayMyName())
+	assert.Equal(t, "CXY", container.MustGet(serviceC).(randomInterface).SayMyName())
+
+	_, err := container.Get(decorator)
+	assert.ErrorIs(t, err, ErrUnknownService)
+}
+
+func TestDecoratorWhen(t *testing.T) {
+	const serviceA = "service.a"
+	const serviceB = "service.b"
+	const serviceC = "service.c"
+	const serviceD = "service.d"
+	const paramTracing = "feature.tracing"
+
+	decorate := func(name string) Factory {
+		return WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return &decoratorService{
+				randomService: randomService{Name: name},
+				Decorated:     ctx.Decorated().(randomInterface),
+			}, nil
+		})
+	}
+
+	container := Builder(
+		Param(paramTracing, true),
+		Service(serviceA, WithInstance(&randomService{Name: "A"})),
+		Decorator(serviceB, serviceA, decorate("B")).WhenParam(paramTracing, true),
+		Decorator(serviceC, serviceA, decorate("C")).WhenParam(paramTracing, false),
+		Decorator(serviceD, serviceA, decorate("D")).
+			When(func(ctx FactoryCtx) bool {
+				return ctx.Decorated() != nil
+			}).
+			When(func(ctx FactoryCtx) bool {
+				return ctx.ServiceID() == serviceD
+			}),
+	).MustBuild(context.TODO())
+
+	assert.Equal(t, "ABD", container.MustGet(serviceA).(randomInterface).SayMyName())
+	assert.Same(t, container.MustGet(serviceB), container.MustGet(serviceC))
+}
@@ -1,17 +1,52 @@
 package dimple
 
+import "fmt"
+
 var _ Definition = (*definition)(nil)
 
 type definition struct {
-	id string
+	id        string
+	namespace string
+	private   bool
+	source    uintptr
 }
 
 func (s *definition) clone() *definition {
 	return &definition{
-		id: s.id,
+		id:        s.id,
+		namespace: s.namespace,
+		private:   s.private,
+		source:    s.source,
 	}
 }
 
 func (s *definition) Id() string {
 	return s.id
 }
+
+func (s *definition) Namespace() string {
+	return s.namespace
+}
+
+func (s *definition) Source() string {
+	return sourceOf(s.source)
+}
+
+func (s *definition) Private() bool {
+	return s.private
+}
+
+// withNamespace returns a copy of the definition whose ID is prefixed by the namespace
+func withNamespace(def Definition, namespace string) Definition {
+	id := fmt.Sprintf(`%s.%s`, namespace, def.Id())
+	switch t := def.(type) {
+	case DecoratorDef:
+		return t.WithID(id).WithNamespace(namespace)
+	case ServiceDef:
+		return t.WithID(id).WithNamespace(namespace)
+	case ParamDef:
+		return t.WithID(id).WithNamespace(namespace)
+	default:
+		panic(fmt.Sprintf(`unsupported type of definiton "%T" for service "%s"`, def, def.Id()))
+	}
+}
@@ -0,0 +1,147 @@
+// Package dimplehttp integrates dimple containers with net/http.
+package dimplehttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/phramz/dimple"
+)
+
+const (
+	// RequestID is the ID of the *http.Request within the request scoped container
+	RequestID = "http.request"
+	// ResponseWriterID is the ID of the http.ResponseWriter within the request scoped container
+	ResponseWriterID = "http.response_writer"
+	// RequestScopeTag is the tag of services which are instantiated per request by Middleware(). Services depending
+	// on the request, the response writer or other request scoped services need to carry it.
+	RequestScopeTag = "http.request_scope"
+	// RouteTag is the tag of services which will be mounted by Mount(). The attributes "method" and "path"
+	// describe the route e.g. WithTag(RouteTag, "method", "GET", "path", "/users").
+	RouteTag = "http.route"
+)
+
+// ErrInvalidRoute is returned by Mount() if a route is incomplete or has been registered twice
+var ErrInvalidRoute = errors.New("invalid route")
+
+// Middleware returns a middleware opening a request scoped container for each request. Services tagged by
+// RequestScopeTag are instantiated per request while all others are resolved from the given container, so they
+// are shared across requests. The *http.Request, the http.ResponseWriter and the context of the request are
+// available as services of the request scope. The request scope will be shut down after the handler returned, even
+// if the request has been cancelled. Opening the scope copies the definitions of the container, so each request
+// takes time linear to the number of definitions on top of the handler.
+func Middleware(c dimple.Container) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scope := c.Scope(RequestScopeTag)
+			defer func() {
+				// errors are reported to the listeners of the container. The request context is most likely done
+				// once the client went away, but request scoped services need to be closed anyway.
+				_ = scope.Shutdown(context.WithoutCancel(r.Context()))
+			}()
+
+			r = r.WithContext(dimple.WithContainer(r.Context(), scope))
+			scope.Override(dimple.Service("context", dimple.WithInstance(r.Context())))
+			scope.Override(dimple.Service(RequestID, dimple.WithInstance(r)))
+			scope.Override(dimple.Service(ResponseWriterID, dimple.WithInstance(w)))
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// FromRequest returns the container carried by the request context, which is the request scoped container opened
+// by Middleware(), or nil if there is none
+func FromRequest(r *http.Request) dimple.Container {
+	return dimple.FromContext(r.Context())
+}
+
+// Mount registers all services tagged by RouteTag at the given mux. The services have to be either a
+// http.Handler or a func(http.ResponseWriter, *http.Request). They are resolved on each request from
+// the request scoped container if there is one, or the given container otherwise.
+func Mount(mux *http.ServeMux, c dimple.Container) error {
+	routes := make(map[string]map[string]string)
+	paths := make([]string, 0)
+	for _, id := range c.Tagged(RouteTag) {
+		for _, tag := range c.Tags(id) {
+			if tag.Name != RouteTag {
+				continue
+			}
+
+			path := tag.Attribute("path")
+			if path == "" {
+				return fmt.Errorf(`%w: service "%s" is missing the attribute "path" of tag "%s"`, ErrInvalidRoute, id, RouteTag)
+			}
+
+			if _, ok := routes[path]; !ok {
+				routes[path] = make(map[string]string)
+				paths = append(paths, path)
+			}
+
+			method := strings.ToUpper(tag.Attribute("method"))
+			if other, ok := routes[path][method]; ok {
+				route := strings.TrimSpace(fmt.Sprintf(`%s %s`, method, path))
+				return fmt.Errorf(`%w: route "%s" of service "%s" has already been registered by "%s"`, ErrInvalidRoute, route, id, other)
+			}
+
+			routes[path][method] = id
+		}
+	}
+
+	for _, path := range paths {
+		mux.Handle(path, &router{container: c, routes: routes[path]})
+	}
+
+	return nil
+}
+
+// router dispatches the requests of a path to the service registered for its method
+type router struct {
+	container dimple.Container
+	routes    map[string]string
+}
+
+func (rt *router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id, ok := rt.routes[r.Method]
+	if !ok {
+		// a route without method matches any method
+		id, ok = rt.routes[""]
+	}
+
+	if !ok {
+		allowed := make([]string, 0, len(rt.routes))
+		for method := range rt.routes {
+			allowed = append(allowed, method)
+		}
+
+		sort.Strings(allowed)
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	c := FromRequest(r)
+	if c == nil {
+		c = rt.container
+	}
+
+	handler, err := c.GetCtx(r.Context(), id)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	switch h := handler.(type) {
+	case http.Handler:
+		h.ServeHTTP(w, r)
+	case func(http.ResponseWriter, *http.Request):
+		h(w, r)
+	default:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
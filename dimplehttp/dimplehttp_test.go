@@ -0,0 +1,205 @@
+// nolint
+package dimplehttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/phramz/dimple"
+	"github.com/stretchr/testify/assert"
+)
+
+type requestLogger struct {
+	Request *http.Request `inject:"http.request"`
+	closed  *int
+}
+
+func (l *requestLogger) Close() error {
+	*l.closed++
+	return nil
+}
+
+type userHandler struct {
+	Logger *requestLogger `inject:"logger"`
+}
+
+func (h *userHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	_, _ = fmt.Fprintf(w, "%s %s", r.Method, h.Logger.Request.URL.Path)
+}
+
+func TestMiddleware(t *testing.T) {
+	closed := 0
+	c := dimple.Builder(
+		dimple.Service("logger", dimple.WithFn(func() any {
+			return &requestLogger{closed: &closed}
+		})).WithTag(RequestScopeTag),
+		dimple.Service("handler.users", dimple.WithFn(func() any {
+			return &userHandler{}
+		})).WithTag(RouteTag, "method", "GET", "path", "/users").WithTag(RequestScopeTag),
+		dimple.Service("handler.create_user", dimple.WithInstance(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		})).WithTag(RouteTag, "method", "post", "path", "/users"),
+		dimple.Service("handler.any", dimple.WithInstance(http.NotFoundHandler())).
+			WithTag(RouteTag, "path", "/any"),
+	).
+		MustBuild(context.TODO())
+
+	var scopes []dimple.Container
+	handler := Middleware(c)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope := FromRequest(r)
+		scopes = append(scopes, scope)
+
+		assert.Same(t, r, scope.MustGet(RequestID))
+		assert.Equal(t, w, scope.MustGet(ResponseWriterID))
+		assert.Equal(t, r.Context(), scope.MustGet("context"))
+		assert.Same(t, r, scope.MustGet("logger").(*requestLogger).Request)
+
+		logger, err := dimple.GetFromCtx[*requestLogger](r.Context(), "logger")
+		assert.NoError(t, err)
+		assert.Same(t, scope.MustGet("logger"), logger)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/b", nil))
+
+	assert.Len(t, scopes, 2)
+	assert.NotSame(t, scopes[0].MustGet("logger"), scopes[1].MustGet("logger"))
+	assert.Equal(t, 2, closed)
+
+	// the application container remains untouched
+	assert.False(t, c.Has(RequestID))
+	assert.Nil(t, FromRequest(httptest.NewRequest(http.MethodGet, "/", nil)))
+}
+
+type pool struct {
+	closed *int
+}
+
+func (p *pool) Close() error {
+	*p.closed++
+	return nil
+}
+
+type poolAwareLogger struct {
+	Pool    *pool         `inject:"pool"`
+	Request *http.Request `inject:"http.request"`
+}
+
+func TestMiddlewareSharesSingletons(t *testing.T) {
+	created, closed := 0, 0
+	c := dimple.Builder(
+		dimple.Service("pool", dimple.WithFn(func() any {
+			created++
+			return &pool{closed: &closed}
+		})),
+		dimple.Service("logger", dimple.WithFn(func() any {
+			return &poolAwareLogger{}
+		})).WithTag(RequestScopeTag),
+	).
+		MustBuild(context.TODO())
+
+	pools := make([]*pool, 0)
+	loggers := make([]*poolAwareLogger, 0)
+	handler := Middleware(c)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger := FromRequest(r).MustGet("logger").(*poolAwareLogger)
+		assert.Same(t, r, logger.Request)
+
+		loggers = append(loggers, logger)
+		pools = append(pools, FromRequest(r).MustGet("pool").(*pool))
+	}))
+
+	for i := 0; i < 3; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	// the lazy singleton is instantiated once by the application container and outlives the requests
+	assert.Equal(t, 1, created)
+	assert.Equal(t, 0, closed)
+	assert.Same(t, c.MustGet("pool"), pools[0])
+	assert.Same(t, pools[0], pools[2])
+	assert.Same(t, pools[0], loggers[1].Pool)
+	assert.NotSame(t, loggers[0], loggers[1])
+
+	assert.NoError(t, c.Shutdown(context.TODO()))
+	assert.Equal(t, 1, closed)
+}
+
+func TestMiddlewareCancelledRequest(t *testing.T) {
+	closed := 0
+	c := dimple.Builder(
+		dimple.Service("logger", dimple.WithFn(func() any {
+			return &requestLogger{closed: &closed}
+		})).WithTag(RequestScopeTag),
+	).
+		MustBuild(context.TODO())
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	handler := Middleware(c)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = FromRequest(r).MustGet("logger")
+
+		// the client went away
+		cancel()
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+	assert.Equal(t, 1, closed)
+}
+
+func TestMount(t *testing.T) {
+	closed := 0
+	c := dimple.Builder(
+		dimple.Service("logger", dimple.WithFn(func() any {
+			return &requestLogger{closed: &closed}
+		})).WithTag(RequestScopeTag),
+		dimple.Service("handler.users", dimple.WithFn(func() any {
+			return &userHandler{}
+		})).WithTag(RouteTag, "method", "GET", "path", "/users").WithTag(RequestScopeTag),
+		dimple.Service("handler.create_user", dimple.WithInstance(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		})).WithTag(RouteTag, "method", "post", "path", "/users"),
+		dimple.Service("handler.any", dimple.WithInstance(http.NotFoundHandler())).
+			WithTag(RouteTag, "path", "/any"),
+	).
+		MustBuild(context.TODO())
+
+	mux := http.NewServeMux()
+	assert.NoError(t, Mount(mux, c))
+	handler := Middleware(c)(mux)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "GET /users", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/users", nil))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/users", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET, POST", rec.Header().Get("Allow"))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/any", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestMountInvalidRoutes(t *testing.T) {
+	c := dimple.Builder(
+		dimple.Service("handler.a", dimple.WithInstance(http.NotFoundHandler())).WithTag(RouteTag, "method", "GET"),
+	).
+		MustBuild(context.TODO())
+	assert.ErrorIs(t, Mount(http.NewServeMux(), c), ErrInvalidRoute)
+
+	c = dimple.Builder(
+		dimple.Service("handler.a", dimple.WithInstance(http.NotFoundHandler())).WithTag(RouteTag, "path", "/a"),
+		dimple.Service("handler.b", dimple.WithInstance(http.NotFoundHandler())).WithTag(RouteTag, "path", "/a"),
+	).
+		MustBuild(context.TODO())
+
+	assert.ErrorContains(t, Mount(http.NewServeMux(), c), `route "/a" of service "handler.b" has already been registered by "handler.a"`)
+}
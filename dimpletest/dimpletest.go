@@ -0,0 +1,124 @@
+// Package dimpletest provides helpers for testing code wired with dimple containers.
+package dimpletest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/phramz/dimple"
+)
+
+// New builds a container from the given definitions and shuts it down once the test has finished
+func New(t testing.TB, defs ...dimple.Definition) *dimple.DefaultContainer {
+	t.Helper()
+
+	c, err := dimple.Builder(defs...).Build(context.Background())
+	if err != nil {
+		t.Fatalf("cannot build container: %v", err)
+		return nil
+	}
+
+	t.Cleanup(func() {
+		if err := c.Shutdown(context.Background()); err != nil {
+			t.Errorf("cannot shutdown container: %v", err)
+		}
+	})
+
+	return c
+}
+
+// Fork returns a fork of the given container sharing its instantiated services, so the test can override
+// definitions without affecting other tests. Services instantiated by the fork are shut down once the test has finished.
+func Fork(t testing.TB, c dimple.Container) *dimple.DefaultContainer {
+	t.Helper()
+
+	f, ok := c.Fork().(*dimple.DefaultContainer)
+	if !ok {
+		t.Fatalf("cannot fork container of type %T", c)
+		return nil
+	}
+
+	t.Cleanup(func() {
+		if err := f.Shutdown(context.Background()); err != nil {
+			t.Errorf("cannot shutdown forked container: %v", err)
+		}
+	})
+
+	return f
+}
+
+// Override replaces the service by given id with the given instance e.g. a mock. This works for services
+// which have been instantiated or decorated already, in which case the decorators will be bypassed.
+func Override(t testing.TB, c dimple.Container, id string, instance any) {
+	t.Helper()
+
+	if !c.Has(id) {
+		t.Fatalf(`cannot override unknown service "%s"`, id)
+		return
+	}
+
+	c.Override(dimple.Service(id, dimple.WithInstance(instance)))
+}
+
+// AssertResolvable asserts that every given id can be resolved without error
+func AssertResolvable(t testing.TB, c dimple.Container, ids ...string) bool {
+	t.Helper()
+
+	ok := true
+	for _, id := range ids {
+		if err := resolve(c, id); err != nil {
+			t.Errorf(`service "%s" is not resolvable: %v`, id, err)
+			ok = false
+		}
+	}
+
+	return ok
+}
+
+// AssertNoCycles asserts that no public service is part of a circular dependency
+func AssertNoCycles(t testing.TB, c *dimple.DefaultContainer) bool {
+	t.Helper()
+
+	ok := true
+	for _, id := range publicServiceIDs(c) {
+		if err := resolve(c, id); errors.Is(err, dimple.ErrCircularDependency) {
+			t.Errorf(`service "%s" has a circular dependency: %v`, id, err)
+			ok = false
+		}
+	}
+
+	return ok
+}
+
+// BootAll instantiates every public service and reports each failure instead of stopping at the first one
+func BootAll(t testing.TB, c *dimple.DefaultContainer) bool {
+	t.Helper()
+
+	return AssertResolvable(t, c, publicServiceIDs(c)...)
+}
+
+func publicServiceIDs(c *dimple.DefaultContainer) []string {
+	ids := make([]string, 0)
+	for _, info := range c.Definitions() {
+		if info.Kind == "service" && !info.Private {
+			ids = append(ids, info.ID)
+		}
+	}
+
+	return ids
+}
+
+// resolve gets the service by given id and converts a panicking factory into an error
+func resolve(c dimple.Container, id string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: panic: %v", dimple.ErrServiceFactoryFailed, r)
+		}
+	}()
+
+	_, err = c.Get(id)
+
+	return err
+}
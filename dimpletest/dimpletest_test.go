@@ -0,0 +1,188 @@
+// nolint
+package dimpletest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/phramz/dimple"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeT struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeT) Fatalf(format string, args ...any) {
+	f.Errorf(format, args...)
+}
+
+func (f *fakeT) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+func (f *fakeT) finish() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+type greeter interface {
+	Greet() string
+}
+
+type closingGreeter struct {
+	greeting string
+	closed   bool
+}
+
+func (g *closingGreeter) Greet() string {
+	return g.greeting
+}
+
+func (g *closingGreeter) Close() error {
+	g.closed = true
+	return nil
+}
+
+type shoutingGreeter struct {
+	inner greeter
+}
+
+func (g *shoutingGreeter) Greet() string {
+	return g.inner.Greet() + "!"
+}
+
+func TestNew(t *testing.T) {
+	ft := &fakeT{}
+	provided := &closingGreeter{greeting: "hi"}
+	c := New(ft,
+		dimple.Service("greeter", dimple.WithFn(func() any {
+			return &closingGreeter{greeting: "hello"}
+		})),
+		dimple.Service("greeter.provided", dimple.WithInstance(provided)),
+	)
+
+	g := c.MustGet("greeter").(*closingGreeter)
+	assert.False(t, g.closed)
+	assert.Same(t, provided, c.MustGet("greeter.provided"))
+
+	ft.finish()
+	assert.True(t, g.closed)
+	assert.False(t, provided.closed, "instances passed in are not closed")
+	assert.Empty(t, ft.errors)
+}
+
+func TestOverride(t *testing.T) {
+	c := New(t,
+		dimple.Service("greeter", dimple.WithInstance(&closingGreeter{greeting: "hello"})),
+		dimple.Decorator("greeter.shout", "greeter", dimple.WithContextFn(func(ctx dimple.FactoryCtx) (any, error) {
+			return &shoutingGreeter{inner: ctx.Decorated().(greeter)}, nil
+		})),
+	)
+
+	assert.Equal(t, "hello!", c.MustGet("greeter").(greeter).Greet())
+
+	mock := &closingGreeter{greeting: "mock"}
+	Override(t, c, "greeter", mock)
+	assert.Same(t, mock, c.MustGet("greeter"))
+	assert.NoError(t, c.Shutdown(context.Background()))
+	assert.False(t, mock.closed)
+
+	ft := &fakeT{}
+	Override(ft, c, "unknown", mock)
+	assert.Len(t, ft.errors, 1)
+}
+
+func TestFork(t *testing.T) {
+	c := New(t, dimple.Service("greeter", dimple.WithInstance(&closingGreeter{greeting: "hello"})))
+	g := c.MustGet("greeter")
+
+	ft := &fakeT{}
+	f := Fork(ft, c)
+	assert.Same(t, g, f.MustGet("greeter"))
+
+	mock := &closingGreeter{greeting: "mock"}
+	Override(t, f, "greeter", mock)
+	assert.Same(t, mock, f.MustGet("greeter"))
+	assert.Same(t, g, c.MustGet("greeter"))
+
+	ft.finish()
+	assert.False(t, g.(*closingGreeter).closed)
+	assert.Empty(t, ft.errors)
+}
+
+func TestAssertResolvable(t *testing.T) {
+	c := New(t,
+		dimple.Service("ok", dimple.WithInstance(&closingGreeter{})),
+		dimple.Service("failing", dimple.WithErrorFn(func() (any, error) {
+			return nil, errors.New("boom")
+		})),
+		dimple.Service("panicking", dimple.WithFn(func() any {
+			panic("oops")
+		})),
+	)
+
+	ft := &fakeT{}
+	assert.True(t, AssertResolvable(ft, c, "ok"))
+	assert.Empty(t, ft.errors)
+
+	assert.False(t, AssertResolvable(ft, c, "ok", "failing", "panicking", "unknown"))
+	assert.Len(t, ft.errors, 3)
+	assert.Contains(t, ft.errors[0], `"failing"`)
+	assert.Contains(t, ft.errors[1], "oops")
+	assert.Contains(t, ft.errors[2], `"unknown"`)
+}
+
+func TestAssertNoCycles(t *testing.T) {
+	ft := &fakeT{}
+	c := New(ft,
+		dimple.Service("a", dimple.WithContextFn(func(ctx dimple.FactoryCtx) (any, error) {
+			return ctx.Container().Get("b")
+		})),
+		dimple.Service("b", dimple.WithContextFn(func(ctx dimple.FactoryCtx) (any, error) {
+			return ctx.Container().Get("a")
+		})),
+		dimple.Service("c", dimple.WithErrorFn(func() (any, error) {
+			return nil, errors.New("not a cycle")
+		})),
+	)
+
+	assert.False(t, AssertNoCycles(ft, c))
+	assert.Len(t, ft.errors, 2)
+
+	assert.True(t, AssertNoCycles(t, New(t, dimple.Service("ok", dimple.WithInstance(&closingGreeter{})))))
+}
+
+func TestBootAll(t *testing.T) {
+	ft := &fakeT{}
+	c := New(ft,
+		dimple.Service("ok", dimple.WithInstance(&closingGreeter{})),
+		dimple.Service("failing.1", dimple.WithErrorFn(func() (any, error) {
+			return nil, errors.New("boom 1")
+		})),
+		dimple.Service("failing.2", dimple.WithErrorFn(func() (any, error) {
+			return nil, errors.New("boom 2")
+		})),
+		dimple.Service("private", dimple.WithErrorFn(func() (any, error) {
+			return nil, errors.New("never resolved")
+		})).WithPrivate(),
+	)
+
+	assert.False(t, BootAll(ft, c))
+	assert.Len(t, ft.errors, 2)
+	assert.Contains(t, ft.errors[0], "boom 1")
+	assert.Contains(t, ft.errors[1], "boom 2")
+
+	assert.True(t, BootAll(t, New(t, dimple.Service("ok", dimple.WithInstance(&closingGreeter{})))))
+}
@@ -0,0 +1,77 @@
+package dimpletest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/phramz/dimple"
+)
+
+var _ dimple.Tracer = (*TraceRecorder)(nil)
+
+// RecordedSpan is a span recorded by the TraceRecorder
+type RecordedSpan struct {
+	Name       string
+	Attributes map[string]string
+	Parent     *RecordedSpan
+	Err        error
+	Ended      bool
+	recorder   *TraceRecorder
+}
+
+func (s *RecordedSpan) SetError(err error) {
+	s.recorder.Lock()
+	defer s.recorder.Unlock()
+
+	s.Err = err
+}
+
+func (s *RecordedSpan) End() {
+	s.recorder.Lock()
+	defer s.recorder.Unlock()
+
+	s.Ended = true
+}
+
+// TraceRecorder is a dimple.Tracer recording all spans in memory, so they can be asserted in tests
+type TraceRecorder struct {
+	sync.Mutex
+	spans []*RecordedSpan
+}
+
+type spanKey struct{}
+
+func (r *TraceRecorder) Start(ctx context.Context, name string, attributes map[string]string) (context.Context, dimple.Span) {
+	parent, _ := ctx.Value(spanKey{}).(*RecordedSpan)
+	span := &RecordedSpan{
+		Name:       name,
+		Attributes: attributes,
+		Parent:     parent,
+		recorder:   r,
+	}
+
+	r.Lock()
+	r.spans = append(r.spans, span)
+	r.Unlock()
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// Spans returns all spans recorded so far in order of their start
+func (r *TraceRecorder) Spans() []*RecordedSpan {
+	r.Lock()
+	defer r.Unlock()
+
+	return append(make([]*RecordedSpan, 0, len(r.spans)), r.spans...)
+}
+
+// Span returns the first span recorded for the service by given id or nil if there is none
+func (r *TraceRecorder) Span(id string) *RecordedSpan {
+	for _, span := range r.Spans() {
+		if span.Attributes[dimple.TraceAttrServiceID] == id {
+			return span
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+// nolint
+package dimpletest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/phramz/dimple"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceRecorder(t *testing.T) {
+	r := &TraceRecorder{}
+
+	c := dimple.Builder(
+		dimple.Service("greeter", dimple.WithContextFn(func(ctx dimple.FactoryCtx) (any, error) {
+			return &closingGreeter{greeting: dimple.MustGetT[string](ctx.Container(), "greeting")}, nil
+		})),
+		dimple.Param("greeting", "hello"),
+		dimple.Decorator("greeter.shout", "greeter", dimple.WithContextFn(func(ctx dimple.FactoryCtx) (any, error) {
+			return &shoutingGreeter{inner: ctx.Decorated().(greeter)}, nil
+		})),
+		dimple.Service("app", dimple.WithContextFn(func(ctx dimple.FactoryCtx) (any, error) {
+			return ctx.Container().Get("greeter")
+		})),
+		dimple.Service("failing", dimple.WithContextFn(func(ctx dimple.FactoryCtx) (any, error) {
+			return nil, errors.New("boom")
+		})),
+	).
+		WithTracer(r).
+		MustBuild(context.Background())
+
+	_ = c.MustGet("app")
+	_, err := c.Get("failing")
+	assert.Error(t, err)
+
+	app := r.Span("app")
+	assert.Equal(t, "instantiate app", app.Name)
+	assert.Nil(t, app.Parent)
+	assert.Equal(t, "service", app.Attributes[dimple.TraceAttrKind])
+
+	decorator := r.Span("greeter.shout")
+	assert.Same(t, app, decorator.Parent)
+	assert.Equal(t, "decorator", decorator.Attributes[dimple.TraceAttrKind])
+
+	origin := r.Span("greeter")
+	assert.Same(t, decorator, origin.Parent)
+	assert.Equal(t, "service", origin.Attributes[dimple.TraceAttrKind])
+
+	// params are not instantiated
+	assert.Nil(t, r.Span("greeting"))
+
+	failing := r.Span("failing")
+	assert.ErrorIs(t, failing.Err, dimple.ErrServiceFactoryFailed)
+
+	for _, span := range r.Spans() {
+		assert.True(t, span.Ended, span.Name)
+	}
+}
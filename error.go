@@ -1,6 +1,10 @@
 package dimple
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 var (
 	// ErrCircularDependency is returned when a dependency cycle has been detected
@@ -9,4 +13,113 @@ var (
 	ErrUnknownService = errors.New("unknown service")
 	// ErrServiceFactoryFailed is returned when the factory cannot instantiate the service
 	ErrServiceFactoryFailed = errors.New("factory failed to instantiate service")
+	// ErrPrivateService is returned when a private service is requested from outside of its namespace
+	ErrPrivateService = errors.New("private service")
+	// ErrDuplicateModule is returned when a module of the same name has already been installed
+	ErrDuplicateModule = errors.New("duplicate module")
+	// ErrUnknownModule is returned if a module depends on a module which has not been installed
+	ErrUnknownModule = errors.New("unknown module")
+	// ErrDefinitionConflict is returned when a definition ID has already been registered
+	ErrDefinitionConflict = errors.New("conflicting definition")
+	// ErrResolutionCancelled is returned when the context of the resolution has been cancelled or exceeded its deadline
+	ErrResolutionCancelled = errors.New("resolution cancelled")
+	// ErrAmbiguousType is returned when more than one definition is assignable to the type of a method argument
+	ErrAmbiguousType = errors.New("ambiguous type")
+	// ErrInvalidParam is returned when a parameter cannot be set since the definition is not a plain parameter
+	ErrInvalidParam = errors.New("invalid parameter")
+	// ErrNoContainer is returned when a context does not carry a container
+	ErrNoContainer = errors.New("no container in context")
 )
+
+// factoryError is returned when a factory failed to instantiate a service. It matches ErrServiceFactoryFailed
+// as well as the error returned by the factory.
+type factoryError struct {
+	msg string
+	err error
+}
+
+func newFactoryError(def Definition, err error) error {
+	return &factoryError{
+		msg: fmt.Sprintf(`%s: cannot instantiate service "%s: %s"%s`, ErrServiceFactoryFailed, def.Id(), err.Error(), declaredAt(def)),
+		err: err,
+	}
+}
+
+func (e *factoryError) Error() string {
+	return e.msg
+}
+
+func (e *factoryError) Unwrap() error {
+	return e.err
+}
+
+func (e *factoryError) Is(target error) bool {
+	return target == ErrServiceFactoryFailed
+}
+
+// cancelledError is returned when the resolution of a service has been cancelled. It matches ErrResolutionCancelled
+// as well as the error of the context.
+type cancelledError struct {
+	msg string
+	err error
+}
+
+func newCancelledError(id string, err error) error {
+	return &cancelledError{
+		msg: fmt.Sprintf(`%s: cannot resolve service "%s": %s`, ErrResolutionCancelled, id, err.Error()),
+		err: err,
+	}
+}
+
+func (e *cancelledError) Error() string {
+	return e.msg
+}
+
+func (e *cancelledError) Unwrap() error {
+	return e.err
+}
+
+func (e *cancelledError) Is(target error) bool {
+	return target == ErrResolutionCancelled
+}
+
+// multiError combines multiple errors into one
+type multiError struct {
+	errs []error
+}
+
+// joinErrors returns a single error combining all errs or nil if there is none
+func joinErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	if len(errs) == 1 {
+		return errs[0]
+	}
+
+	return &multiError{errs: errs}
+}
+
+func (m *multiError) Error() string {
+	msgs := make([]string, 0, len(m.errs))
+	for _, err := range m.errs {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+func (m *multiError) Unwrap() []error {
+	return m.errs
+}
+
+func (m *multiError) Is(target error) bool {
+	for _, err := range m.errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
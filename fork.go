@@ -0,0 +1,120 @@
+package dimple
+
+// fork returns a new root container sharing the definitions until either of both containers writes to them
+func (c *DefaultContainer) fork(fresh bool) *DefaultContainer {
+	if c.parent != nil {
+		return c.parent.fork(fresh)
+	}
+
+	c.Lock()
+	f := c.newFork()
+	f.definitions = c.definitions
+	f.order = c.order
+	f.shared = true
+	c.shared = true
+
+	if fresh {
+		definitions := make(map[string]Definition, len(c.definitions))
+		for id, def := range c.definitions {
+			definitions[id] = withoutInstance(def)
+		}
+
+		f.definitions = definitions
+		f.order = append(make([]string, 0, len(c.order)), c.order...)
+		f.shared = false
+	} else {
+		// instances which are shared have been created by the original container along with their dependencies
+		for id, deps := range c.dependencies {
+			f.dependencies[id] = append(make([]string, 0, len(deps)), deps...)
+		}
+	}
+	c.Unlock()
+
+	return f.attach()
+}
+
+// scope returns a fork which instantiates only the services carrying the tag and the definitions overridden
+// within the scope. All other services are resolved from the container the scope has been created from.
+func (c *DefaultContainer) scope(tag string) *DefaultContainer {
+	root := c.getRoot()
+
+	root.Lock()
+	// the definitions are copied right away rather than shared, since the scope writes to them anyway. That way
+	// the container the scope has been created from does not need to copy them on its next write.
+	f := root.newFork()
+	f.definitions = make(map[string]Definition, len(root.definitions))
+	for id, def := range root.definitions {
+		f.definitions[id] = def
+	}
+	f.order = append(make([]string, 0, len(root.order)), root.order...)
+	root.Unlock()
+
+	// shared services are instantiated by the base along with their dependencies
+	f.base = root
+	f.scopeTag = tag
+	f.scoped = map[string]bool{"container": true, "context": true}
+
+	return f.attach()
+}
+
+// newFork returns a new root container with the settings of this one but without definitions. The caller has to
+// hold the lock.
+func (c *DefaultContainer) newFork() *DefaultContainer {
+	f := &DefaultContainer{
+		booted:       c.booted,
+		ctx:          c.ctx,
+		dependencies: make(map[string][]string),
+		listeners:    c.listeners,
+		tracer:       c.tracer,
+		probeTypes:   c.probeTypes,
+	}
+	f.eager.Store(c.eager.Load())
+
+	return f
+}
+
+// attach registers the fork as the container of its builtin services
+func (c *DefaultContainer) attach() *DefaultContainer {
+	c.set("container", builtin("container", c))
+	if c.ctx != nil {
+		c.ctx = WithContainer(c.ctx, c)
+		c.set("context", builtin("context", c.ctx))
+	}
+
+	return c
+}
+
+// isScoped returns TRUE if the service by given id is instantiated within the scope rather than shared with the
+// container the scope has been created from. Decorators belong to the scope of the service they decorate.
+func (c *DefaultContainer) isScoped(id string) bool {
+	id = c.getGroup(id)[0]
+
+	c.Lock()
+	scoped := c.scoped[id]
+	c.Unlock()
+
+	if scoped {
+		return true
+	}
+
+	_, ok := c.getTag(id, c.scopeTag)
+
+	return ok
+}
+
+// withoutInstance returns the definition without any instance, so it will be instantiated again on next use
+func withoutInstance(def Definition) Definition {
+	switch t := def.(type) {
+	case DecoratorDef:
+		dec := t.WithInstance(nil)
+		if t.Decorated() != nil {
+			dec = dec.WithDecorated(withoutInstance(t.Decorated()))
+		}
+
+		return dec
+	case ServiceDef:
+		return t.WithInstance(nil)
+	default:
+		return def
+	}
+}
@@ -0,0 +1,131 @@
+// nolint
+package dimple
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainer_Fork(t *testing.T) {
+	ctn := Builder(
+		Param("param.name", "A"),
+		Service("service.a", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return &randomService{Name: MustGetT[string](ctx.Container(), "param.name")}, nil
+		})),
+		Decorator("decorator.a", "service.a", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return &randomService{Name: ctx.Decorated().(*randomService).Name + " decorated"}, nil
+		})),
+	).
+		MustBuild(context.TODO())
+
+	a := ctn.MustGet("service.a")
+
+	fork := ctn.Fork()
+	assert.Same(t, a, fork.MustGet("service.a"))
+	assert.Same(t, fork, fork.MustGet("container"))
+	assert.Same(t, ctn, ctn.MustGet("container"))
+
+	fork.Override(Param("param.name", "B"))
+	fork.Override(Service("service.b", WithInstance(&randomService{Name: "B"})))
+	assert.Equal(t, "B", fork.MustGet("param.name"))
+	assert.True(t, fork.Has("service.b"))
+
+	assert.Equal(t, "A", ctn.MustGet("param.name"))
+	assert.False(t, ctn.Has("service.b"))
+
+	ctn.Override(Param("param.name", "C"))
+	assert.Equal(t, "B", fork.MustGet("param.name"))
+}
+
+func TestContainer_ForkFresh(t *testing.T) {
+	ctn := Builder(
+		Param("param.name", "A"),
+		Service("service.a", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return &randomService{Name: MustGetT[string](ctx.Container(), "param.name")}, nil
+		})),
+		Decorator("decorator.a", "service.a", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return &randomService{Name: ctx.Decorated().(*randomService).Name + " decorated"}, nil
+		})),
+	).
+		MustBuild(context.TODO())
+
+	a := ctn.MustGet("service.a")
+	assert.Equal(t, "A decorated", a.(*randomService).Name)
+
+	fork := ctn.ForkFresh()
+	fork.Override(Param("param.name", "B"))
+
+	b := fork.MustGet("service.a")
+	assert.NotSame(t, a, b)
+	assert.Equal(t, "B decorated", b.(*randomService).Name)
+	assert.Same(t, a, ctn.MustGet("service.a"))
+}
+
+func TestContainer_ForkConcurrent(t *testing.T) {
+	ctn := Builder(
+		Param("param.name", "A"),
+		Service("service.a", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return &randomService{Name: MustGetT[string](ctx.Container(), "param.name")}, nil
+		})),
+		Decorator("decorator.a", "service.a", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return &randomService{Name: ctx.Decorated().(*randomService).Name + " decorated"}, nil
+		})),
+	).
+		MustBuild(context.TODO())
+
+	done := make(chan bool)
+	for i := 0; i < 10; i++ {
+		go func() {
+			fork := ctn.ForkFresh()
+			fork.Override(Param("param.name", "B"))
+			_ = fork.MustGet("service.a")
+			done <- true
+		}()
+	}
+
+	_ = ctn.MustGet("service.a")
+	for i := 0; i < 10; i++ {
+		<-done
+	}
+
+	assert.Equal(t, "A decorated", ctn.MustGet("service.a").(*randomService).Name)
+}
+
+func TestContainer_Scope(t *testing.T) {
+	ctn := Builder(
+		Param("param.name", "A"),
+		Service("service.shared", WithFn(func() any { return &randomService{Name: "shared"} })),
+		Service("service.scoped", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return &randomService{
+				Name: MustGetT[string](ctx.Container(), "param.name"),
+				A:    MustGetT[*randomService](ctx.Container(), "service.shared"),
+			}, nil
+		})).WithTag("request"),
+		Decorator("decorator.scoped", "service.scoped", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return &randomService{Name: "decorated", B: ctx.Decorated().(*randomService)}, nil
+		})),
+	).
+		MustBuild(context.TODO())
+
+	a := ctn.Scope("request")
+	b := ctn.Scope("request")
+	b.Override(Param("param.name", "B"))
+
+	scopedA := a.MustGet("service.scoped").(*randomService)
+	scopedB := b.MustGet("service.scoped").(*randomService)
+	assert.NotSame(t, scopedA, scopedB)
+	assert.Equal(t, "decorated", scopedA.Name)
+	assert.Equal(t, "A", scopedA.B.Name)
+	assert.Equal(t, "B", scopedB.B.Name)
+	assert.Same(t, a, a.MustGet("container"))
+
+	// services outside of the scope are instantiated once by the container the scopes have been created from
+	assert.Same(t, ctn.MustGet("service.shared"), scopedA.B.A)
+	assert.Same(t, scopedA.B.A, scopedB.B.A)
+	assert.Equal(t, "A", ctn.MustGet("param.name"))
+
+	// the container does not share its definitions with its scopes, so it does not have to copy them on writes
+	assert.False(t, ctn.shared)
+}
@@ -1,6 +1,6 @@
 module github.com/phramz/dimple
 
-go 1.19
+go 1.21
 
 require (
 	github.com/stretchr/testify v1.9.0
@@ -0,0 +1,186 @@
+package dimple
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// HealthTag is the tag of services which will be instantiated if necessary to check their health
+	HealthTag = "health"
+	// DefaultHealthCheckTimeout is the timeout of each health check unless the health tag of the service
+	// has a "timeout" attribute e.g. WithTag(HealthTag, "timeout", "2s")
+	DefaultHealthCheckTimeout = 5 * time.Second
+)
+
+// HealthChecker is implemented by services which are able to check their health. Services having
+// a method Ping(ctx context.Context) error are checked as well.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// HealthReport is the result of Container.Health()
+type HealthReport struct {
+	Healthy  bool                     `json:"healthy"`
+	Services map[string]ServiceHealth `json:"services"`
+}
+
+// ServiceHealth is the result of the health check of a single service
+type ServiceHealth struct {
+	Healthy  bool          `json:"healthy"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"-"`
+}
+
+func (s ServiceHealth) MarshalJSON() ([]byte, error) {
+	type alias ServiceHealth
+
+	return json.Marshal(struct {
+		alias
+		Duration string `json:"duration"`
+	}{
+		alias:    alias(s),
+		Duration: s.Duration.String(),
+	})
+}
+
+func (c *DefaultContainer) Health(ctx context.Context) HealthReport {
+	report := HealthReport{
+		Healthy:  true,
+		Services: make(map[string]ServiceHealth),
+	}
+
+	mu := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	for _, id := range c.getOrder() {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+
+			check, timeout, ok := c.getHealthCheck(ctx, id)
+			if !ok {
+				return
+			}
+
+			health := runHealthCheck(ctx, check, timeout)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Services[id] = health
+			report.Healthy = report.Healthy && health.Healthy
+		}(id)
+	}
+
+	wg.Wait()
+
+	return report
+}
+
+// HealthHandler returns a http.Handler responding the HealthReport of the container as JSON. The status code
+// is 200 if all services are healthy or 503 otherwise.
+func HealthHandler(c Container) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := c.Health(r.Context())
+
+		w.Header().Set("Content-Type", "application/json")
+		if report.Healthy {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
+
+// getHealthCheck returns the health check of the service by given id if it has to be checked. Services tagged by
+// HealthTag are instantiated if necessary, others are only checked if they have been instantiated already.
+func (c *DefaultContainer) getHealthCheck(ctx context.Context, id string) (func(ctx context.Context) error, time.Duration, bool) {
+	def := c.getDefinition(id)
+	if dec, ok := def.(DecoratorDef); ok {
+		if dec.Id() == id {
+			// the decorated service will be checked instead
+			return nil, 0, false
+		}
+
+		def = c.getDefinition(dec.Id())
+	}
+
+	timeout := DefaultHealthCheckTimeout
+	tag, tagged := c.getTag(id, HealthTag)
+	if d, err := time.ParseDuration(tag.Attribute("timeout")); err == nil {
+		timeout = d
+	}
+
+	instance := instanceOf(def)
+	if instance == nil && tagged {
+		var err error
+		if instance, err = c.GetCtx(ctx, id); err != nil {
+			return func(context.Context) error { return err }, timeout, true
+		}
+	}
+
+	switch t := instance.(type) {
+	case HealthChecker:
+		return t.HealthCheck, timeout, true
+	case interface {
+		Ping(ctx context.Context) error
+	}:
+		return t.Ping, timeout, true
+	}
+
+	if tagged {
+		return func(context.Context) error {
+			return fmt.Errorf(`service "%s" of type "%T" tagged "%s" does not implement HealthChecker`, id, instance, HealthTag)
+		}, timeout, true
+	}
+
+	return nil, 0, false
+}
+
+// getTag returns the tag by given name of the service by given id
+func (c *DefaultContainer) getTag(id, name string) (Tag, bool) {
+	for _, t := range c.Tags(id) {
+		if t.Name == name {
+			return t, true
+		}
+	}
+
+	return Tag{}, false
+}
+
+// runHealthCheck runs the check with the given timeout. A check which ignores its context is abandoned
+// once the timeout elapsed.
+func runHealthCheck(ctx context.Context, check func(ctx context.Context) error, timeout time.Duration) ServiceHealth {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	result := make(chan error, 1)
+	go func() {
+		result <- check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-result:
+	case <-ctx.Done():
+		err = fmt.Errorf(`health check timed out after %s: %w`, timeout, ctx.Err())
+	}
+
+	health := ServiceHealth{
+		Healthy:  err == nil,
+		Duration: time.Since(start),
+	}
+
+	if err != nil {
+		health.Error = err.Error()
+	}
+
+	return health
+}
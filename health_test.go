@@ -0,0 +1,98 @@
+// nolint
+package dimple
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type healthCheckedService struct {
+	err error
+}
+
+func (h *healthCheckedService) HealthCheck(_ context.Context) error {
+	return h.err
+}
+
+type pingedService struct {
+	delay time.Duration
+}
+
+func (p *pingedService) Ping(ctx context.Context) error {
+	select {
+	case <-time.After(p.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestContainer_Health(t *testing.T) {
+	ctn := Builder(
+		Service("service.healthy", WithInstance(&healthCheckedService{})),
+		Service("service.unhealthy", WithInstance(&healthCheckedService{err: errors.New("connection refused")})),
+		Service("service.ping", WithInstance(&pingedService{})),
+		Service("service.slow", WithInstance(&pingedService{delay: time.Second})).
+			WithTag(HealthTag, "timeout", "10ms"),
+		Service("service.lazy", WithInstance(&healthCheckedService{})),
+		Service("service.tagged", WithInstance(&healthCheckedService{})).WithTag(HealthTag),
+		Service("service.unchecked", WithInstance(&randomService{})).WithTag(HealthTag),
+		Service("service.plain", WithInstance(&randomService{})),
+		Decorator("decorator.ping", "service.ping", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return ctx.Decorated(), nil
+		})),
+	).
+		MustBuild(context.TODO())
+
+	for _, id := range []string{"service.healthy", "service.unhealthy", "service.ping", "service.slow", "service.plain"} {
+		_ = ctn.MustGet(id)
+	}
+
+	report := ctn.Health(context.TODO())
+	assert.False(t, report.Healthy)
+	assert.Len(t, report.Services, 6)
+
+	assert.True(t, report.Services["service.healthy"].Healthy)
+	assert.True(t, report.Services["service.ping"].Healthy)
+	assert.True(t, report.Services["service.tagged"].Healthy)
+	assert.Equal(t, "connection refused", report.Services["service.unhealthy"].Error)
+	assert.Contains(t, report.Services["service.slow"].Error, "timed out after 10ms")
+	assert.Contains(t, report.Services["service.unchecked"].Error, "does not implement HealthChecker")
+
+	// only instantiated services are checked unless they are tagged
+	assert.NotContains(t, report.Services, "service.lazy")
+	assert.NotContains(t, report.Services, "service.plain")
+	assert.NotContains(t, report.Services, "decorator.ping")
+}
+
+func TestHealthHandler(t *testing.T) {
+	ctn := Builder(
+		Service("service.healthy", WithInstance(&healthCheckedService{})).WithTag(HealthTag),
+	).
+		MustBuild(context.TODO())
+
+	rec := httptest.NewRecorder()
+	HealthHandler(ctn).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	body := map[string]any{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, true, body["healthy"])
+	assert.Equal(t, true, body["services"].(map[string]any)["service.healthy"].(map[string]any)["healthy"])
+	assert.NotEmpty(t, body["services"].(map[string]any)["service.healthy"].(map[string]any)["duration"])
+
+	ctn.Override(Service("service.healthy", WithInstance(&healthCheckedService{err: errors.New("down")})).WithTag(HealthTag))
+
+	rec = httptest.NewRecorder()
+	HealthHandler(ctn).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"error":"down"`)
+}
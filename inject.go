@@ -0,0 +1,188 @@
+package dimple
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// injectPlans caches the compiled injectPlan per reflect.Type of the injection target
+var injectPlans sync.Map
+
+// injectPlan holds everything needed to inject into a certain type, so that the struct needs to be
+// walked via reflection and its tags parsed only once
+type injectPlan struct {
+	fields  []injectField
+	methods []injectMethod
+}
+
+// injectField describes a struct field tagged with `inject`
+type injectField struct {
+	index    int
+	name     string
+	id       string
+	writable bool
+	convert  func(val any) (reflect.Value, bool)
+}
+
+// injectMethod describes a method like InjectLogger whose arguments are resolved by type
+type injectMethod struct {
+	index int
+	name  string
+	args  []reflect.Type
+}
+
+// getInjectPlan returns the cached injectPlan for the given pointer to struct type or compiles a new one
+func getInjectPlan(t reflect.Type) *injectPlan {
+	if plan, ok := injectPlans.Load(t); ok {
+		return plan.(*injectPlan)
+	}
+
+	plan, _ := injectPlans.LoadOrStore(t, compileInjectPlan(t))
+
+	return plan.(*injectPlan)
+}
+
+func compileInjectPlan(t reflect.Type) *injectPlan {
+	plan := &injectPlan{
+		fields:  make([]injectField, 0),
+		methods: make([]injectMethod, 0),
+	}
+
+	elem := t.Elem()
+	for i := 0; i < elem.NumField(); i++ {
+		typeField := elem.Field(i)
+		id, ok := typeField.Tag.Lookup("inject")
+		if !ok {
+			continue
+		}
+
+		fieldType := typeField.Type
+		plan.fields = append(plan.fields, injectField{
+			index:    i,
+			name:     typeField.Name,
+			id:       id,
+			writable: typeField.IsExported(),
+			convert: func(val any) (reflect.Value, bool) {
+				return toValue(val, fieldType)
+			},
+		})
+	}
+
+	for i := 0; i < t.NumMethod(); i++ {
+		method := t.Method(i)
+		if !isInjectMethod(method) {
+			continue
+		}
+
+		args := make([]reflect.Type, 0, method.Type.NumIn()-1)
+		for j := 1; j < method.Type.NumIn(); j++ {
+			args = append(args, method.Type.In(j))
+		}
+
+		plan.methods = append(plan.methods, injectMethod{
+			index: i,
+			name:  method.Name,
+			args:  args,
+		})
+	}
+
+	return plan
+}
+
+// isInjectMethod returns TRUE if the method is named like "Inject" followed by an upper-case letter
+// e.g. InjectLogger and returns either nothing or only an error
+func isInjectMethod(method reflect.Method) bool {
+	name := strings.TrimPrefix(method.Name, "Inject")
+	if name == method.Name || name == "" {
+		return false
+	}
+
+	if r, _ := utf8.DecodeRuneInString(name); !unicode.IsUpper(r) {
+		return false
+	}
+
+	switch method.Type.NumOut() {
+	case 0:
+		return true
+	case 1:
+		return method.Type.Out(0) == errorType
+	default:
+		return false
+	}
+}
+
+// apply injects all dependencies into target using the given container
+func (p *injectPlan) apply(c *DefaultContainer, target reflect.Value) error {
+	fail := func(field, id string, err error) error {
+		c.notify(func(l Listener) {
+			l.OnInjectFailed(target.Interface(), field, id, err)
+		})
+
+		return err
+	}
+
+	v := target.Elem()
+	for _, field := range p.fields {
+		instance, err := c.Get(field.id)
+		if err != nil {
+			return fail(field.name, field.id, err)
+		}
+
+		if !field.writable {
+			return fail(field.name, field.id, fmt.Errorf(`unable to inject value to field "%s" since it is not writable`, field.name))
+		}
+
+		val, ok := field.convert(instance)
+		if !ok {
+			return fail(field.name, field.id, fmt.Errorf(`unable to inject value of type "%T" to field "%s" of type "%s"`, instance, field.name, v.Field(field.index).Type()))
+		}
+
+		v.Field(field.index).Set(val)
+		c.notify(func(l Listener) {
+			l.OnInject(target.Interface(), field.name, field.id)
+		})
+	}
+
+	for _, method := range p.methods {
+		args := make([]reflect.Value, 0, len(method.args))
+		for i, t := range method.args {
+			arg, err := c.getValueByType(t)
+			if err != nil {
+				return fail(method.name, "", fmt.Errorf(`unable to inject argument %d of method "%s": %w`, i+1, method.name, err))
+			}
+
+			args = append(args, arg)
+		}
+
+		if err := c.invoke(target.Method(method.index), args); err != nil {
+			return fail(method.name, "", fmt.Errorf(`unable to inject via method "%s": %w`, method.name, err))
+		}
+	}
+
+	return nil
+}
+
+// toValue converts the value to a reflect.Value of the given type
+func toValue(val any, t reflect.Type) (reflect.Value, bool) {
+	if val == nil {
+		switch t.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice:
+			return reflect.Zero(t), true
+		default:
+			return reflect.Value{}, false
+		}
+	}
+
+	v := reflect.ValueOf(val)
+	if !v.Type().AssignableTo(t) {
+		return reflect.Value{}, false
+	}
+
+	return v, true
+}
@@ -0,0 +1,176 @@
+// nolint
+package dimple
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type planService struct {
+	Name     string          `inject:"param.name"`
+	A        randomInterface `inject:"service.a"`
+	private  string          `inject:"param.name"`
+	Untagged string
+	b        *randomService
+}
+
+func (p *planService) InjectB(b *randomService) {
+	p.b = b
+}
+
+func TestInjectPlan(t *testing.T) {
+	plan := getInjectPlan(reflect.TypeOf(&planService{}))
+	assert.Same(t, plan, getInjectPlan(reflect.TypeOf(&planService{})))
+
+	assert.Len(t, plan.fields, 3)
+	assert.Equal(t, "param.name", plan.fields[0].id)
+	assert.True(t, plan.fields[0].writable)
+	assert.Equal(t, "service.a", plan.fields[1].id)
+	assert.False(t, plan.fields[2].writable)
+
+	assert.Len(t, plan.methods, 1)
+	assert.Equal(t, "InjectB", plan.methods[0].name)
+}
+
+func TestInjectPlan_NotWritable(t *testing.T) {
+	ctn := Builder(
+		Param("param.name", "foo"),
+		Service("service.a", WithInstance(&randomService{Name: "A"})),
+	).
+		MustBuild(context.TODO())
+
+	err := ctn.Inject(&planService{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `field "private"`)
+}
+
+func TestInjectPlan_NotAssignable(t *testing.T) {
+	out := &struct {
+		Name int `inject:"param.name"`
+	}{}
+
+	ctn := Builder(
+		Param("param.name", "foo"),
+		Service("service.a", WithInstance(&randomService{Name: "A"})),
+	).
+		MustBuild(context.TODO())
+
+	err := ctn.Inject(out)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `field "Name"`)
+}
+
+func TestInjectPlan_Concurrent(t *testing.T) {
+	ctn := Builder(
+		Param("param.name", "foo"),
+		Service("service.a", WithInstance(&randomService{Name: "A"})),
+	).
+		MustBuild(context.TODO())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			out := &injectableService{}
+			assert.Error(t, ctn.Inject(out)) // service.b is unknown
+			assert.Same(t, ctn.MustGet("service.a"), out.InjectedA)
+		}()
+	}
+
+	wg.Wait()
+}
+
+type benchmarkService struct {
+	Name  string          `inject:"param.name"`
+	A     randomInterface `inject:"service.a"`
+	Other string
+	More  int
+}
+
+func BenchmarkContainer_Inject(b *testing.B) {
+	ctn := Builder(
+		Param("param.name", "foo"),
+		Service("service.a", WithInstance(&randomService{Name: "A"})),
+	).
+		MustBuild(context.TODO())
+	_ = ctn.Boot()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := ctn.Inject(&benchmarkService{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// injectWithoutPlan is a copy of Inject() as it was before plans were cached. It walks the struct and parses
+// its tags on every call.
+func injectWithoutPlan(c *DefaultContainer, target any) error {
+	v := reflect.ValueOf(target).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		typeField := v.Type().Field(i)
+		if id, ok := typeField.Tag.Lookup("inject"); ok {
+			instance, err := c.Get(id)
+			if err != nil {
+				return err
+			}
+
+			fieldVal := v.Field(i)
+			if !fieldVal.CanSet() {
+				return fmt.Errorf(`unable to inject value to field "%s" since it is not writable`, typeField.Name)
+			}
+
+			fieldVal.Set(reflect.ValueOf(instance))
+		}
+	}
+
+	m := reflect.ValueOf(target)
+	for i := 0; i < m.NumMethod(); i++ {
+		method := m.Type().Method(i)
+		if method.Name == "Inject" || !strings.HasPrefix(method.Name, "Inject") {
+			continue
+		}
+
+		args := make([]reflect.Value, 0, method.Type.NumIn()-1)
+		for j := 1; j < method.Type.NumIn(); j++ {
+			arg, err := c.getValueByType(method.Type.In(j))
+			if err != nil {
+				return fmt.Errorf(`unable to inject argument %d of method "%s": %w`, j, method.Name, err)
+			}
+
+			args = append(args, arg)
+		}
+
+		if err := c.invoke(m.Method(i), args); err != nil {
+			return fmt.Errorf(`unable to inject via method "%s": %w`, method.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func BenchmarkContainer_InjectWithoutPlanCache(b *testing.B) {
+	ctn := Builder(
+		Param("param.name", "foo"),
+		Service("service.a", WithInstance(&randomService{Name: "A"})),
+	).
+		MustBuild(context.TODO())
+	_ = ctn.Boot()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := injectWithoutPlan(ctn, &benchmarkService{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package dimple
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// proxies holds the registered ProxyFactory per interface type
+var proxies sync.Map
+
+// Interceptor will be invoked around each method call of an intercepted service. It receives the name of the method,
+// its arguments and next which calls the method of the intercepted service and returns its results.
+// Example:
+//
+//	func (l *LoggingInterceptor) Invoke(ctx context.Context, method string, args []any, next func() []any) []any {
+//		start := time.Now()
+//		defer func() { l.logger.Infof("%s took %s", method, time.Since(start)) }()
+//
+//		return next()
+//	}
+type Interceptor interface {
+	Invoke(ctx context.Context, method string, args []any, next func() []any) []any
+}
+
+// InterceptorFunc is an adapter to use an ordinary function as Interceptor
+type InterceptorFunc func(ctx context.Context, method string, args []any, next func() []any) []any
+
+func (f InterceptorFunc) Invoke(ctx context.Context, method string, args []any, next func() []any) []any {
+	return f(ctx, method, args, next)
+}
+
+// ProxyFactory returns a proxy implementing T which calls the interceptor around each method of target.
+// The given ctx should be passed to the interceptor for methods not accepting a context.Context as first argument.
+// Proxies can be generated using the dimple-proxy command:
+//
+//	//go:generate go run github.com/phramz/dimple/cmd/dimple-proxy -type Repository
+type ProxyFactory[T any] func(ctx context.Context, target T, interceptor Interceptor) T
+
+// RegisterProxy registers the ProxyFactory for the interface T
+func RegisterProxy[T any](factory ProxyFactory[T]) {
+	proxies.Store(reflect.TypeOf((*T)(nil)).Elem(), factory)
+}
+
+// Intercept returns a DecoratorDef which wraps the service by given id with a proxy calling the interceptor around
+// each method. T must be an interface implemented by the service and a ProxyFactory must have been registered for it.
+// The decorator is registered by the ID "<id>.interceptor", use WithID() to intercept a service more than once.
+func Intercept[T any](id string, interceptor Interceptor) DecoratorDef {
+	dec := &decoratorDef{
+		definition: definition{
+			id:     fmt.Sprintf(`%s.interceptor`, id),
+			source: captureSource(2),
+		},
+		decorates: id,
+	}
+
+	return dec.WithFactory(WithContextFn(func(ctx FactoryCtx) (any, error) {
+		t := reflect.TypeOf((*T)(nil)).Elem()
+		if t.Kind() != reflect.Interface {
+			return nil, fmt.Errorf(`cannot intercept service "%s" since "%s" is not an interface`, id, t)
+		}
+
+		factory, ok := proxies.Load(t)
+		if !ok {
+			return nil, fmt.Errorf(`cannot intercept service "%s" since there is no proxy registered for "%s"`, id, t)
+		}
+
+		target, ok := ctx.Decorated().(T)
+		if !ok {
+			return nil, fmt.Errorf(`cannot intercept service "%s" of type "%T" since it does not implement "%s"`, id, ctx.Decorated(), t)
+		}
+
+		return factory.(ProxyFactory[T])(ctx.Ctx(), target, interceptor), nil
+	}))
+}
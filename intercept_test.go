@@ -0,0 +1,97 @@
+// nolint
+package dimple
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// randomInterfaceProxy is what dimple-proxy would generate for randomInterface
+type randomInterfaceProxy struct {
+	ctx         context.Context
+	target      randomInterface
+	interceptor Interceptor
+}
+
+func newRandomInterfaceProxy(ctx context.Context, target randomInterface, interceptor Interceptor) randomInterface {
+	return &randomInterfaceProxy{ctx: ctx, target: target, interceptor: interceptor}
+}
+
+func (p *randomInterfaceProxy) SayMyName() string {
+	out := p.interceptor.Invoke(p.ctx, "SayMyName", []any{}, func() []any {
+		r0 := p.target.SayMyName()
+
+		return []any{r0}
+	})
+
+	r0, _ := out[0].(string)
+
+	return r0
+}
+
+func TestIntercept(t *testing.T) {
+	const serviceA = "service.a"
+
+	RegisterProxy[randomInterface](newRandomInterfaceProxy)
+
+	calls := make([]string, 0)
+	interceptor := func(name string) Interceptor {
+		return InterceptorFunc(func(ctx context.Context, method string, args []any, next func() []any) []any {
+			calls = append(calls, name+":"+method)
+			out := next()
+			out[0] = out[0].(string) + name
+
+			return out
+		})
+	}
+
+	origin := &randomService{Name: "A"}
+	container := Builder(
+		Service(serviceA, WithInstance(origin)),
+		Intercept[randomInterface](serviceA, interceptor("B")),
+		Intercept[randomInterface](serviceA, interceptor("C")).WithID("service.a.interceptor.c"),
+	).MustBuild(context.TODO())
+
+	assert.Equal(t, []string{"service.a.interceptor", "service.a.interceptor.c"}, container.DecoratorChain(serviceA))
+
+	a := container.MustGet(serviceA).(randomInterface)
+	assert.IsType(t, &randomInterfaceProxy{}, a)
+	assert.Equal(t, "ABC", a.SayMyName())
+	assert.Equal(t, []string{"C:SayMyName", "B:SayMyName"}, calls)
+	assert.Same(t, origin, a.(*randomInterfaceProxy).target.(*randomInterfaceProxy).target)
+}
+
+func TestInterceptErrors(t *testing.T) {
+	const serviceA = "service.a"
+
+	RegisterProxy[randomInterface](newRandomInterfaceProxy)
+
+	interceptor := InterceptorFunc(func(ctx context.Context, method string, args []any, next func() []any) []any {
+		return next()
+	})
+
+	for _, tt := range []struct {
+		def      Definition
+		expected string
+	}{
+		{def: Intercept[*randomService](serviceA, interceptor), expected: `is not an interface`},
+		{def: Intercept[injectableInterface](serviceA, interceptor), expected: `no proxy registered`},
+		{def: Intercept[randomInterface](serviceA, interceptor).WithDecorates("param.b"), expected: `does not implement`},
+	} {
+		container := Builder(
+			Service(serviceA, WithInstance(&randomService{Name: "A"})),
+			Param("param.b", "B"),
+			tt.def,
+		).MustBuild(context.TODO())
+
+		_, err := container.Get(tt.def.Id())
+		assert.ErrorIs(t, err, ErrServiceFactoryFailed)
+		assert.ErrorContains(t, err, tt.expected)
+	}
+}
+
+type injectableInterface interface {
+	Inject(target any) error
+}
@@ -0,0 +1,85 @@
+package dimple
+
+import (
+	"time"
+)
+
+var _ Listener = NopListener{}
+
+// Listener is notified about what the container does. It is the extension point for logging, metrics and tracing.
+// Listeners are called synchronously and must be safe for concurrent use. Embed NopListener to implement only
+// the callbacks you are interested in.
+type Listener interface {
+	// OnDefinitionAdded is called when a definition has been added or overridden
+	OnDefinitionAdded(def Definition)
+	// BeforeInstantiate is called before the factory of the service or decorator by given id is called
+	BeforeInstantiate(id string)
+	// AfterInstantiate is called after the factory of the service or decorator by given id has returned
+	AfterInstantiate(id string, instance any, duration time.Duration, err error)
+	// OnRewire is called on Build() for each decorated service along with its decorators from the innermost
+	// to the outermost one
+	OnRewire(id string, decorators []string)
+	// OnDecorate is called when the service by given id has been decorated by the given decorator
+	OnDecorate(id string, decorator string)
+	// OnInject is called when the service or param by given id has been injected into the field of target
+	OnInject(target any, field string, id string)
+	// OnInjectFailed is called when the injection into the field or method of target failed. The id is empty
+	// for methods since their arguments are resolved by type.
+	OnInjectFailed(target any, field string, id string, err error)
+	// AfterBoot is called after Container.Boot() or Container.BootParallel() with the number of services booted
+	AfterBoot(services int, duration time.Duration, err error)
+	// OnShutdown is called when the service by given id has been closed by Container.Shutdown(), Reset() or SetParam()
+	OnShutdown(id string, err error)
+	// AfterShutdown is called after Container.Shutdown() with the number of services closed
+	AfterShutdown(services int, duration time.Duration, err error)
+}
+
+// NopListener implements every callback of Listener doing nothing
+type NopListener struct{}
+
+func (NopListener) OnDefinitionAdded(Definition) {}
+
+func (NopListener) BeforeInstantiate(string) {}
+
+func (NopListener) AfterInstantiate(string, any, time.Duration, error) {}
+
+func (NopListener) OnRewire(string, []string) {}
+
+func (NopListener) OnDecorate(string, string) {}
+
+func (NopListener) OnInject(any, string, string) {}
+
+func (NopListener) OnInjectFailed(any, string, string, error) {}
+
+func (NopListener) AfterBoot(int, time.Duration, error) {}
+
+func (NopListener) OnShutdown(string, error) {}
+
+func (NopListener) AfterShutdown(int, time.Duration, error) {}
+
+// WithListener registers a listener. It is notified about the definitions which have been added already as well.
+func (b *DefaultBuilder) WithListener(l Listener) *DefaultBuilder {
+	c := b.container
+	c.Lock()
+	c.listeners = append(c.listeners, l)
+	c.Unlock()
+
+	for _, id := range c.getOrder() {
+		l.OnDefinitionAdded(c.getDefinition(id))
+	}
+
+	return b
+}
+
+// notify calls fn for each registered listener
+func (c *DefaultContainer) notify(fn func(l Listener)) {
+	root := c.getRoot()
+
+	root.Lock()
+	listeners := root.listeners
+	root.Unlock()
+
+	for _, l := range listeners {
+		fn(l)
+	}
+}
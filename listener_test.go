@@ -0,0 +1,109 @@
+// nolint
+package dimple
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingListener struct {
+	NopListener
+	sync.Mutex
+	events []string
+}
+
+func (r *recordingListener) record(format string, args ...any) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.events = append(r.events, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingListener) OnDefinitionAdded(def Definition) {
+	r.record("added %s", def.Id())
+}
+
+func (r *recordingListener) BeforeInstantiate(id string) {
+	r.record("before %s", id)
+}
+
+func (r *recordingListener) AfterInstantiate(id string, instance any, duration time.Duration, err error) {
+	r.record("after %s %T %v", id, instance, err)
+}
+
+func (r *recordingListener) OnDecorate(id string, decorator string) {
+	r.record("decorate %s by %s", id, decorator)
+}
+
+func (r *recordingListener) OnInject(target any, field string, id string) {
+	r.record("inject %s into %T.%s", id, target, field)
+}
+
+func (r *recordingListener) OnShutdown(id string, err error) {
+	r.record("shutdown %s %v", id, err)
+}
+
+type listenedService struct {
+	Param string `inject:"param.a"`
+}
+
+func (l *listenedService) Close() error {
+	return errors.New("boom")
+}
+
+func TestListener(t *testing.T) {
+	l := &recordingListener{}
+
+	b := Builder(Param("param.a", "A"))
+	b.WithListener(l)
+	b.Add(Service("service.a", WithFn(func() any { return &listenedService{} })))
+	b.Add(Decorator("decorator.a", "service.a", WithContextFn(func(ctx FactoryCtx) (any, error) {
+		return ctx.Decorated(), nil
+	})))
+	b.Add(Service("service.b", WithErrorFn(func() (any, error) { return nil, errors.New("failed") })))
+
+	ctn := b.MustBuild(context.TODO())
+	_ = ctn.MustGet("service.a")
+	_, _ = ctn.Get("service.b")
+	_ = ctn.Shutdown(context.TODO())
+
+	assert.Equal(t, []string{
+		"added container",
+		"added param.a",
+		"added service.a",
+		"added decorator.a",
+		"added service.b",
+		"added context",
+		"before service.a",
+		"after service.a *dimple.listenedService <nil>",
+		"inject param.a into *dimple.listenedService.Param",
+		"before decorator.a",
+		"after decorator.a *dimple.listenedService <nil>",
+		"inject param.a into *dimple.listenedService.Param",
+		"decorate service.a by decorator.a",
+		"before service.b",
+		`after service.b <nil> factory failed to instantiate service: cannot instantiate service "service.b: failed"`,
+		"shutdown service.a boom",
+	}, filterEvents(l.events))
+}
+
+// filterEvents strips the source locations from the events
+func filterEvents(events []string) []string {
+	filtered := make([]string, 0, len(events))
+	for _, e := range events {
+		if i := strings.Index(e, " declared at "); i >= 0 {
+			e = e[:i]
+		}
+
+		filtered = append(filtered, e)
+	}
+
+	return filtered
+}
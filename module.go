@@ -0,0 +1,88 @@
+package dimple
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thoas/go-funk"
+)
+
+// Module groups definitions which belong together, e.g. all services provided by a package
+type Module struct {
+	// Name identifies the module. It must be unique within a ContainerBuilder.
+	Name string
+	// Namespace is an optional prefix applied to the IDs of all definitions of the module,
+	// e.g. "auth" turns "token_store" into "auth.token_store"
+	Namespace string
+	// Definitions will be added to the builder on installation
+	Definitions []Definition
+	// DependsOn lists the names of the modules which must be installed before this one
+	DependsOn []string
+	// Configure is an optional hook called after the definitions of the module have been added
+	Configure func(b ContainerBuilder) error
+}
+
+// id returns the ID of the definition within the namespace of the module
+func (m Module) id(def Definition) string {
+	if m.Namespace == "" {
+		return def.Id()
+	}
+
+	return fmt.Sprintf(`%s.%s`, m.Namespace, def.Id())
+}
+
+// sortModules returns the modules ordered by their dependencies. Dependencies must either be part
+// of modules or have been installed already.
+func sortModules(modules []Module, installed []string) ([]Module, error) {
+	byName := make(map[string]Module)
+	for _, m := range modules {
+		if _, ok := byName[m.Name]; ok || funk.ContainsString(installed, m.Name) {
+			return nil, fmt.Errorf(`%w: module "%s" has already been installed`, ErrDuplicateModule, m.Name)
+		}
+
+		byName[m.Name] = m
+	}
+
+	sorted := make([]Module, 0, len(modules))
+	done := make(map[string]bool)
+
+	var visit func(m Module, path []string) error
+	visit = func(m Module, path []string) error {
+		if done[m.Name] {
+			return nil
+		}
+
+		path = append(path, m.Name)
+		for _, dep := range m.DependsOn {
+			if funk.ContainsString(installed, dep) {
+				continue
+			}
+
+			if funk.ContainsString(path, dep) {
+				return fmt.Errorf(`%w: modules "%s" -> "%s"`, ErrCircularDependency, strings.Join(path, `" -> "`), dep)
+			}
+
+			depModule, ok := byName[dep]
+			if !ok {
+				return fmt.Errorf(`%w: module "%s" depends on "%s" which has not been installed`, ErrUnknownModule, m.Name, dep)
+			}
+
+			if err := visit(depModule, path); err != nil {
+				return err
+			}
+		}
+
+		done[m.Name] = true
+		sorted = append(sorted, m)
+
+		return nil
+	}
+
+	for _, m := range modules {
+		if err := visit(m, make([]string, 0)); err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
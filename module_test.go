@@ -0,0 +1,267 @@
+// nolint
+package dimple
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultBuilder_Install(t *testing.T) {
+	installed := make([]string, 0)
+	configure := func(name string) func(b ContainerBuilder) error {
+		return func(b ContainerBuilder) error {
+			installed = append(installed, name)
+			assert.True(t, b.Has(fmt.Sprintf(`service.%s`, name)))
+
+			return nil
+		}
+	}
+
+	db := Module{
+		Name:        "db",
+		Definitions: []Definition{Service("service.db", WithInstance(&randomService{Name: "db"}))},
+		Configure:   configure("db"),
+	}
+	auth := Module{
+		Name:        "auth",
+		DependsOn:   []string{"db"},
+		Definitions: []Definition{Service("service.auth", WithInstance(&randomService{Name: "auth"}))},
+		Configure:   configure("auth"),
+	}
+	api := Module{
+		Name:      "api",
+		DependsOn: []string{"auth", "db"},
+		Configure: func(b ContainerBuilder) error {
+			installed = append(installed, "api")
+			b.Add(Service("service.api", WithInstance(&randomService{Name: "api"})))
+
+			return nil
+		},
+	}
+
+	b := Builder()
+	assert.NoError(t, b.Install(api, auth, db))
+	assert.Equal(t, []string{"db", "auth", "api"}, installed)
+	assert.Equal(t, "api", b.owners["service.api"])
+
+	c := b.MustBuild(context.TODO())
+	assert.Equal(t, "auth", c.MustGet("service.auth").(randomInterface).SayMyName())
+	assert.Equal(t, "api", c.MustGet("service.api").(randomInterface).SayMyName())
+}
+
+func TestDefaultBuilder_InstallErrors(t *testing.T) {
+	db := Module{
+		Name:        "db",
+		Definitions: []Definition{Service("service.db", WithInstance(&randomService{}))},
+	}
+
+	b := Builder(Param("param.a", "A"))
+	assert.NoError(t, b.Install(db))
+
+	for _, tt := range []struct {
+		modules  []Module
+		err      error
+		expected string
+	}{
+		{
+			modules:  []Module{db},
+			err:      ErrDuplicateModule,
+			expected: `module "db" has already been installed`,
+		},
+		{
+			modules:  []Module{{Name: "a"}, {Name: "a"}},
+			err:      ErrDuplicateModule,
+			expected: `module "a" has already been installed`,
+		},
+		{
+			modules:  []Module{{Name: "a", DependsOn: []string{"db", "b"}}},
+			err:      ErrUnknownModule,
+			expected: `module "a" depends on "b" which has not been installed`,
+		},
+		{
+			modules:  []Module{{Name: "a", DependsOn: []string{"b"}}, {Name: "b", DependsOn: []string{"a"}}},
+			err:      ErrCircularDependency,
+			expected: `modules "a" -> "b" -> "a"`,
+		},
+		{
+			modules:  []Module{{Name: "a", Definitions: []Definition{Param("service.db", "db")}}},
+			err:      ErrDefinitionConflict,
+			expected: `module "a" registers "service.db" which has already been registered by module "db"`,
+		},
+		{
+			modules:  []Module{{Name: "a", Definitions: []Definition{Param("param.a", "A")}}},
+			err:      ErrDefinitionConflict,
+			expected: `module "a" registers "param.a" which has already been registered outside of any module`,
+		},
+		{
+			modules: []Module{
+				{Name: "a", Definitions: []Definition{Param("param.b", "B")}},
+				{Name: "b", Definitions: []Definition{Param("param.b", "B")}},
+			},
+			err:      ErrDefinitionConflict,
+			expected: `module "b" registers "param.b" which has already been registered by module "a"`,
+		},
+	} {
+		err := b.Install(tt.modules...)
+		assert.ErrorIs(t, err, tt.err)
+		assert.ErrorContains(t, err, tt.expected)
+	}
+
+	assert.False(t, b.Has("param.b"))
+}
+
+func TestDefaultBuilder_InstallConflictNamesModules(t *testing.T) {
+	auth := Module{
+		Name: "auth",
+		Definitions: []Definition{
+			Param("db", "auth"),
+		},
+	}
+	cache := Module{
+		Name: "cache",
+		Configure: func(b ContainerBuilder) error {
+			b.Add(Param("db", "cache"))
+			return nil
+		},
+	}
+
+	b := Builder()
+	assert.NoError(t, b.Install(auth, cache))
+	b.Add(Param("db", "global"))
+
+	_, err := b.Build(context.TODO())
+	assert.ErrorIs(t, err, ErrDefinitionConflict)
+	assert.Regexp(t, `"db" registered at .+ by module "cache" has already been registered at .+ by module "auth"`, err.Error())
+	assert.Regexp(t, `"db" registered at [^ ]+ has already been registered at .+ by module "auth"`, err.Error())
+}
+
+func TestDefaultBuilder_InstallConfigureError(t *testing.T) {
+	err := Builder().Install(Module{
+		Name: "a",
+		Configure: func(b ContainerBuilder) error {
+			return fmt.Errorf("failed")
+		},
+	})
+
+	assert.ErrorContains(t, err, `cannot configure module "a": failed`)
+}
+
+type tokenConsumer struct {
+	Store *randomService `inject:"auth.token_store"`
+}
+
+func TestDefaultBuilder_InstallNamespace(t *testing.T) {
+	auth := Module{
+		Name:      "auth",
+		Namespace: "auth",
+		Definitions: []Definition{
+			Service("token_store", WithInstance(&randomService{Name: "store"})).WithPrivate(),
+			Service("service", WithContextFn(func(ctx FactoryCtx) (any, error) {
+				store, err := ctx.Container().Get("token_store") // relative to the namespace
+
+				return &randomService{Name: "auth", A: store.(*randomService)}, err
+			})),
+			Service("consumer", WithInstance(&tokenConsumer{})),
+			Decorator("service.decorator", "service", WithContextFn(func(ctx FactoryCtx) (any, error) {
+				return &decoratorService{
+					randomService: randomService{Name: "decorated"},
+					Decorated:     ctx.Decorated().(randomInterface),
+				}, nil
+			})),
+		},
+	}
+	api := Module{
+		Name: "api",
+		Definitions: []Definition{
+			Service("service.api", WithContextFn(func(ctx FactoryCtx) (any, error) {
+				return ctx.Container().Get("auth.token_store")
+			})),
+		},
+	}
+
+	b := Builder()
+	assert.NoError(t, b.Install(auth, api))
+	assert.True(t, b.Has("auth.token_store"))
+	assert.False(t, b.Has("token_store"))
+	assert.Equal(t, "auth", b.owners["auth.token_store"])
+
+	c := b.MustBuild(context.TODO())
+	assert.Equal(t, []string{"auth.service.decorator"}, c.DecoratorChain("auth.service"))
+
+	svc := c.MustGet("auth.service").(*decoratorService)
+	assert.Equal(t, "authdecorated", svc.SayMyName())
+	assert.Equal(t, "store", svc.Decorated.(*randomService).A.Name)
+	assert.Equal(t, "store", c.MustGet("auth.consumer").(*tokenConsumer).Store.Name)
+
+	_, err := c.Get("auth.token_store")
+	assert.ErrorIs(t, err, ErrPrivateService)
+
+	_, err = c.Get("service.api")
+	assert.ErrorIs(t, err, ErrPrivateService)
+
+	err = c.Inject(&tokenConsumer{})
+	assert.ErrorIs(t, err, ErrPrivateService)
+}
+
+func TestDefaultBuilder_InstallNamespacePrecedence(t *testing.T) {
+	auth := Module{
+		Name:      "auth",
+		Namespace: "auth",
+		Definitions: []Definition{
+			Param("name", "auth"),
+			Service("service", WithContextFn(func(ctx FactoryCtx) (any, error) {
+				name, err := ctx.Container().Get("name")
+				if err != nil {
+					return nil, err
+				}
+
+				return &randomService{Name: name.(string)}, nil
+			})),
+		},
+	}
+
+	b := Builder(Param("name", "global"))
+	assert.NoError(t, b.Install(auth))
+	c := b.MustBuild(context.TODO())
+
+	assert.Equal(t, "auth", c.MustGet("auth.service").(*randomService).Name)
+	assert.Equal(t, "global", c.MustGet("name"))
+}
+
+type secretService struct{}
+
+type secretConsumer struct {
+	secret *secretService
+}
+
+func (s *secretConsumer) InjectSecret(secret *secretService) {
+	s.secret = secret
+}
+
+func TestDefaultBuilder_InstallNamespaceInjectByType(t *testing.T) {
+	auth := Module{
+		Name:      "auth",
+		Namespace: "auth",
+		Definitions: []Definition{
+			Service("secret", WithInstance(&secretService{})).WithPrivate(),
+			Service("consumer", WithInstance(&secretConsumer{})),
+		},
+	}
+
+	b := Builder()
+	assert.NoError(t, b.Install(auth))
+	c := b.MustBuild(context.TODO())
+
+	// within the namespace the private service can be injected by type
+	consumer := c.MustGet("auth.consumer").(*secretConsumer)
+	assert.NotNil(t, consumer.secret)
+
+	_, err := c.Get("auth.secret")
+	assert.ErrorIs(t, err, ErrPrivateService)
+
+	err = c.Inject(&secretConsumer{})
+	assert.ErrorIs(t, err, ErrPrivateService)
+}
@@ -6,7 +6,8 @@ var _ ParamDef = (*paramDef)(nil)
 func Param(id string, v any) ParamDef {
 	return &paramDef{
 		definition: definition{
-			id: id,
+			id:     id,
+			source: captureSource(2),
 		},
 		value: v,
 	}
@@ -28,6 +29,20 @@ func (p *paramDef) clone() *paramDef {
 	}
 }
 
+func (p *paramDef) WithNamespace(namespace string) ParamDef {
+	c := p.clone()
+	c.namespace = namespace
+
+	return c
+}
+
+func (p *paramDef) WithPrivate() ParamDef {
+	c := p.clone()
+	c.private = true
+
+	return c
+}
+
 func (p *paramDef) WithID(id string) ParamDef {
 	c := p.clone()
 	c.id = id
@@ -0,0 +1,210 @@
+package dimple
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/thoas/go-funk"
+)
+
+// watcher is a callback registered by Watch()
+type watcher struct {
+	id string
+	fn func(old, new any)
+}
+
+func (c *DefaultContainer) SetParam(id string, v any) error {
+	if c.parent != nil {
+		return c.parent.SetParam(id, v)
+	}
+
+	c.reloading.Lock()
+	defer c.reloading.Unlock()
+
+	param, ok := c.getDefinition(id).(ParamDef)
+	if !ok {
+		if !c.Has(id) {
+			return fmt.Errorf(`%w: cannot find definiton for param "%s"`, ErrUnknownService, id)
+		}
+
+		return fmt.Errorf(`%w: "%s" is either no parameter or it has been decorated`, ErrInvalidParam, id)
+	}
+
+	c.set(id, param.WithValue(v))
+	if c.base != nil {
+		c.Lock()
+		c.scoped[id] = true
+		c.Unlock()
+	}
+	c.replaceCompiled(&compiledValue{value: v}, id)
+
+	dependents := c.getDependents(id)
+	err := c.invalidate(dependents)
+	if err == nil && c.eager.Load() {
+		_, err = c.boot(dependents...)
+	}
+
+	for _, w := range c.getWatchers(id) {
+		w.fn(param.Value(), v)
+	}
+
+	return err
+}
+
+func (c *DefaultContainer) Reset(id string, cascade bool) error {
+	if c.parent != nil {
+		return c.parent.Reset(id, cascade)
+	}
+
+	c.reloading.Lock()
+	defer c.reloading.Unlock()
+
+	if !c.Has(id) {
+		return fmt.Errorf(`%w: cannot find definiton for service "%s"`, ErrUnknownService, id)
+	}
+
+	ids := []string{id}
+	if cascade {
+		ids = append(ids, c.getDependents(id)...)
+	}
+
+	return c.invalidate(ids)
+}
+
+func (c *DefaultContainer) Watch(id string, fn func(old, new any)) func() {
+	root := c.getRoot()
+	w := &watcher{id: id, fn: fn}
+
+	root.Lock()
+	root.watchers = append(root.watchers, w)
+	root.Unlock()
+
+	return func() {
+		root.Lock()
+		defer root.Unlock()
+
+		// the slice is copied since the watchers might be invoked at the moment
+		watchers := make([]*watcher, 0, len(root.watchers))
+		for _, other := range root.watchers {
+			if other != w {
+				watchers = append(watchers, other)
+			}
+		}
+
+		root.watchers = watchers
+	}
+}
+
+// getWatchers returns the watchers of the parameter by given id in order of registration
+func (c *DefaultContainer) getWatchers(id string) []*watcher {
+	c.Lock()
+	defer c.Unlock()
+
+	watchers := make([]*watcher, 0)
+	for _, w := range c.watchers {
+		if w.id == id {
+			watchers = append(watchers, w)
+		}
+	}
+
+	return watchers
+}
+
+// invalidate drops the instances of the services by given IDs along with their decorators, so they will be
+// instantiated again on next use. Instances created by this container are closed in reverse order of their
+// instantiation, while instances shared with the container it has been forked from are left untouched.
+func (c *DefaultContainer) invalidate(ids []string) error {
+	members := make([]string, 0, len(ids))
+	for _, id := range ids {
+		for _, member := range c.getGroup(id) {
+			if !funk.ContainsString(members, member) {
+				members = append(members, member)
+			}
+		}
+	}
+
+	for _, id := range members {
+		switch def := c.getDefinition(id).(type) {
+		case ServiceDef, DecoratorDef:
+			c.set(id, withoutInstance(def))
+		}
+	}
+
+	c.replaceCompiled(nil, members...)
+	c.resetStats(members...)
+
+	c.Lock()
+	closing := make([]instanceRef, 0)
+	instances := make([]instanceRef, 0, len(c.instances))
+	for _, ref := range c.instances {
+		if funk.ContainsString(members, ref.id) {
+			closing = append(closing, ref)
+		} else {
+			instances = append(instances, ref)
+		}
+	}
+
+	c.instances = instances
+	c.Unlock()
+
+	errs := make([]error, 0)
+	for i := len(closing) - 1; i >= 0; i-- {
+		// the context given at Build() might be done already
+		err := closeInstance(context.Background(), closing[i].instance)
+		if err != nil {
+			errs = append(errs, fmt.Errorf(`cannot close service "%s": %w`, closing[i].id, err))
+		}
+
+		c.notify(func(l Listener) {
+			l.OnShutdown(closing[i].id, err)
+		})
+	}
+
+	return joinErrors(errs)
+}
+
+// getGroup returns the IDs sharing the instance of the service by given id. That is the decorated service
+// along with all of its decorators.
+func (c *DefaultContainer) getGroup(id string) []string {
+	if dec, ok := c.getDefinition(id).(DecoratorDef); ok && dec.DecoratesTag() == "" && dec.Decorates() != "" {
+		id = dec.Decorates()
+	}
+
+	group := []string{id}
+	for _, dec := range c.getDecoratorChain(id) {
+		group = append(group, dec.Id())
+	}
+
+	return group
+}
+
+// getDependents returns the IDs of all definitions depending on the definition by given id, directly or
+// transitively, according to the dependencies recorded so far
+func (c *DefaultContainer) getDependents(id string) []string {
+	c.Lock()
+	reverse := make(map[string][]string, len(c.dependencies))
+	for dependent, deps := range c.dependencies {
+		for _, dep := range deps {
+			reverse[dep] = append(reverse[dep], dependent)
+		}
+	}
+	c.Unlock()
+
+	dependents := make([]string, 0)
+	queue := []string{id}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+
+		for _, member := range c.getGroup(next) {
+			for _, dependent := range reverse[member] {
+				if dependent != id && !funk.ContainsString(dependents, dependent) {
+					dependents = append(dependents, dependent)
+					queue = append(queue, dependent)
+				}
+			}
+		}
+	}
+
+	return dependents
+}
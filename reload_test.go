@@ -0,0 +1,374 @@
+// nolint
+package dimple
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type limiterService struct {
+	closableService
+	rps int
+}
+
+func TestContainer_SetParam(t *testing.T) {
+	closed := make([]string, 0)
+	created := 0
+	ctn := Builder(
+		Param("param.rps", 10),
+		Param("param.name", "api"),
+		Service("service.limiter", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			created++
+			rps := MustGetT[int](ctx.Container(), "param.rps")
+
+			return &limiterService{closableService{name: fmt.Sprintf("limiter %d", rps), closed: &closed}, rps}, nil
+		})),
+		Decorator("decorator.limiter", "service.limiter", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return ctx.Decorated(), nil
+		})),
+		Service("service.api", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			limiter := MustGetT[*limiterService](ctx.Container(), "service.limiter")
+
+			return &closableService{name: fmt.Sprintf("api %d", limiter.rps), closed: &closed}, nil
+		})),
+		Service("service.other", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return &closableService{name: MustGetT[string](ctx.Container(), "param.name"), closed: &closed}, nil
+		})),
+	).
+		MustBuild(context.TODO())
+
+	api := ctn.MustGet("service.api").(*closableService)
+	other := ctn.MustGet("service.other")
+	assert.Equal(t, "api 10", api.name)
+
+	assert.NoError(t, ctn.SetParam("param.rps", 20))
+	assert.Equal(t, 20, ctn.MustGet("param.rps"))
+	assert.Equal(t, []string{"api 10", "limiter 10"}, closed)
+
+	// dependents are instantiated again on next use
+	assert.Equal(t, 1, created)
+	assert.Equal(t, "api 20", ctn.MustGet("service.api").(*closableService).name)
+	assert.Equal(t, 20, ctn.MustGet("decorator.limiter").(*limiterService).rps)
+	assert.Equal(t, 2, created)
+	assert.Same(t, other, ctn.MustGet("service.other"))
+
+	// the new instances will be closed on shutdown
+	assert.NoError(t, ctn.Shutdown(context.TODO()))
+	assert.Equal(t, []string{"api 10", "limiter 10", "api 20", "limiter 20", "api"}, closed)
+}
+
+func TestContainer_SetParamInvalid(t *testing.T) {
+	closed := make([]string, 0)
+	ctn := Builder(
+		Param("param.rps", 10),
+		Param("param.name", "api"),
+		Service("service.limiter", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			rps := MustGetT[int](ctx.Container(), "param.rps")
+
+			return &limiterService{closableService{name: fmt.Sprintf("limiter %d", rps), closed: &closed}, rps}, nil
+		})),
+		Decorator("decorator.limiter", "service.limiter", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return ctx.Decorated(), nil
+		})),
+		Service("service.api", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			limiter := MustGetT[*limiterService](ctx.Container(), "service.limiter")
+
+			return &closableService{name: fmt.Sprintf("api %d", limiter.rps), closed: &closed}, nil
+		})),
+		Service("service.other", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return &closableService{name: MustGetT[string](ctx.Container(), "param.name"), closed: &closed}, nil
+		})),
+	).
+		MustBuild(context.TODO())
+
+	assert.ErrorIs(t, ctn.SetParam("param.unknown", 1), ErrUnknownService)
+	assert.ErrorIs(t, ctn.SetParam("service.api", 1), ErrInvalidParam)
+}
+
+func TestContainer_SetParamBooted(t *testing.T) {
+	created := 0
+	closed := make([]string, 0)
+	ctn := Builder(
+		Param("param.rps", 10),
+		Param("param.name", "api"),
+		Service("service.limiter", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			created++
+			rps := MustGetT[int](ctx.Container(), "param.rps")
+
+			return &limiterService{closableService{name: fmt.Sprintf("limiter %d", rps), closed: &closed}, rps}, nil
+		})),
+		Decorator("decorator.limiter", "service.limiter", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return ctx.Decorated(), nil
+		})),
+		Service("service.api", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			limiter := MustGetT[*limiterService](ctx.Container(), "service.limiter")
+
+			return &closableService{name: fmt.Sprintf("api %d", limiter.rps), closed: &closed}, nil
+		})),
+		Service("service.other", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return &closableService{name: MustGetT[string](ctx.Container(), "param.name"), closed: &closed}, nil
+		})),
+	).
+		MustBuild(context.TODO())
+
+	assert.NoError(t, ctn.Boot())
+	assert.Equal(t, 1, created)
+
+	// dependents are instantiated right away
+	assert.NoError(t, ctn.SetParam("param.rps", 20))
+	assert.Equal(t, 2, created)
+	assert.Equal(t, "api 20", ctn.MustGet("service.api").(*closableService).name)
+	assert.Equal(t, 2, created)
+}
+
+func TestContainer_SetParamCompiled(t *testing.T) {
+	closed := make([]string, 0)
+	ctn := Builder(
+		Param("param.rps", 10),
+		Param("param.name", "api"),
+		Service("service.limiter", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			rps := MustGetT[int](ctx.Container(), "param.rps")
+
+			return &limiterService{closableService{name: fmt.Sprintf("limiter %d", rps), closed: &closed}, rps}, nil
+		})),
+		Decorator("decorator.limiter", "service.limiter", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return ctx.Decorated(), nil
+		})),
+		Service("service.api", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			limiter := MustGetT[*limiterService](ctx.Container(), "service.limiter")
+
+			return &closableService{name: fmt.Sprintf("api %d", limiter.rps), closed: &closed}, nil
+		})),
+		Service("service.other", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return &closableService{name: MustGetT[string](ctx.Container(), "param.name"), closed: &closed}, nil
+		})),
+	).
+		MustBuild(context.TODO())
+
+	assert.NoError(t, ctn.Compile())
+	assert.Equal(t, "api 10", ctn.MustGet("service.api").(*closableService).name)
+
+	assert.NoError(t, ctn.SetParam("param.rps", 20))
+	assert.Equal(t, 20, ctn.MustGet("param.rps"))
+	assert.Equal(t, "api 20", ctn.MustGet("service.api").(*closableService).name)
+}
+
+func TestContainer_SetParamFork(t *testing.T) {
+	closed := make([]string, 0)
+	ctn := Builder(
+		Param("param.rps", 10),
+		Param("param.name", "api"),
+		Service("service.limiter", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			rps := MustGetT[int](ctx.Container(), "param.rps")
+
+			return &limiterService{closableService{name: fmt.Sprintf("limiter %d", rps), closed: &closed}, rps}, nil
+		})),
+		Decorator("decorator.limiter", "service.limiter", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return ctx.Decorated(), nil
+		})),
+		Service("service.api", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			limiter := MustGetT[*limiterService](ctx.Container(), "service.limiter")
+
+			return &closableService{name: fmt.Sprintf("api %d", limiter.rps), closed: &closed}, nil
+		})),
+		Service("service.other", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return &closableService{name: MustGetT[string](ctx.Container(), "param.name"), closed: &closed}, nil
+		})),
+	).
+		MustBuild(context.TODO())
+
+	api := ctn.MustGet("service.api")
+
+	fork := ctn.Fork()
+	assert.NoError(t, fork.SetParam("param.rps", 20))
+	assert.Equal(t, "api 20", fork.MustGet("service.api").(*closableService).name)
+
+	// instances shared with the original container are left untouched
+	assert.Empty(t, closed)
+	assert.Same(t, api, ctn.MustGet("service.api"))
+	assert.Equal(t, 10, ctn.MustGet("param.rps"))
+}
+
+func TestContainer_Watch(t *testing.T) {
+	closed := make([]string, 0)
+	ctn := Builder(
+		Param("param.rps", 10),
+		Param("param.name", "api"),
+		Service("service.limiter", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			rps := MustGetT[int](ctx.Container(), "param.rps")
+
+			return &limiterService{closableService{name: fmt.Sprintf("limiter %d", rps), closed: &closed}, rps}, nil
+		})),
+		Decorator("decorator.limiter", "service.limiter", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return ctx.Decorated(), nil
+		})),
+		Service("service.api", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			limiter := MustGetT[*limiterService](ctx.Container(), "service.limiter")
+
+			return &closableService{name: fmt.Sprintf("api %d", limiter.rps), closed: &closed}, nil
+		})),
+		Service("service.other", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return &closableService{name: MustGetT[string](ctx.Container(), "param.name"), closed: &closed}, nil
+		})),
+	).
+		MustBuild(context.TODO())
+
+	changes := make([]string, 0)
+	cancel := ctn.Watch("param.rps", func(old, new any) {
+		// dependents have been invalidated already
+		api := ctn.MustGet("service.api").(*closableService)
+		changes = append(changes, fmt.Sprintf("%v -> %v (%s)", old, new, api.name))
+	})
+	ctn.Watch("param.name", func(old, new any) {
+		changes = append(changes, fmt.Sprintf("%v -> %v", old, new))
+	})
+
+	assert.NoError(t, ctn.SetParam("param.rps", 20))
+	assert.NoError(t, ctn.SetParam("param.name", "web"))
+	assert.Equal(t, []string{"10 -> 20 (api 20)", "api -> web"}, changes)
+
+	cancel()
+	assert.NoError(t, ctn.SetParam("param.rps", 30))
+	assert.Len(t, changes, 2)
+}
+
+func TestContainer_Reset(t *testing.T) {
+	closed := make([]string, 0)
+	created := 0
+	ctn := Builder(
+		Param("param.rps", 10),
+		Param("param.name", "api"),
+		Service("service.limiter", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			created++
+			rps := MustGetT[int](ctx.Container(), "param.rps")
+
+			return &limiterService{closableService{name: fmt.Sprintf("limiter %d", rps), closed: &closed}, rps}, nil
+		})),
+		Decorator("decorator.limiter", "service.limiter", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return ctx.Decorated(), nil
+		})),
+		Service("service.api", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			limiter := MustGetT[*limiterService](ctx.Container(), "service.limiter")
+
+			return &closableService{name: fmt.Sprintf("api %d", limiter.rps), closed: &closed}, nil
+		})),
+		Service("service.other", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return &closableService{name: MustGetT[string](ctx.Container(), "param.name"), closed: &closed}, nil
+		})),
+	).
+		MustBuild(context.TODO())
+
+	api := ctn.MustGet("service.api")
+	limiter := ctn.MustGet("service.limiter")
+
+	assert.NoError(t, ctn.Reset("service.limiter", false))
+	assert.Equal(t, []string{"limiter 10"}, closed)
+
+	// dependents keep the instance they depend on
+	assert.Same(t, api, ctn.MustGet("service.api"))
+	assert.NotSame(t, limiter, ctn.MustGet("service.limiter"))
+	assert.Same(t, ctn.MustGet("service.limiter"), ctn.MustGet("decorator.limiter"))
+	assert.Equal(t, 2, created)
+
+	// resetting a decorator resets the decorated service
+	assert.NoError(t, ctn.Reset("decorator.limiter", true))
+	// instances are closed in reverse order of their instantiation
+	assert.Equal(t, []string{"limiter 10", "limiter 10", "api 10"}, closed)
+	assert.NotSame(t, api, ctn.MustGet("service.api"))
+	assert.Equal(t, 3, created)
+
+	assert.ErrorIs(t, ctn.Reset("service.unknown", true), ErrUnknownService)
+}
+
+func TestContainer_ResetCompiled(t *testing.T) {
+	closed := make([]string, 0)
+	ctn := Builder(
+		Param("param.rps", 10),
+		Param("param.name", "api"),
+		Service("service.limiter", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			rps := MustGetT[int](ctx.Container(), "param.rps")
+
+			return &limiterService{closableService{name: fmt.Sprintf("limiter %d", rps), closed: &closed}, rps}, nil
+		})),
+		Decorator("decorator.limiter", "service.limiter", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return ctx.Decorated(), nil
+		})),
+		Service("service.api", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			limiter := MustGetT[*limiterService](ctx.Container(), "service.limiter")
+
+			return &closableService{name: fmt.Sprintf("api %d", limiter.rps), closed: &closed}, nil
+		})),
+		Service("service.other", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return &closableService{name: MustGetT[string](ctx.Container(), "param.name"), closed: &closed}, nil
+		})),
+	).
+		MustBuild(context.TODO())
+
+	assert.NoError(t, ctn.Compile())
+
+	api := ctn.MustGet("service.api")
+	assert.NoError(t, ctn.Reset("param.rps", true))
+	assert.NotSame(t, api, ctn.MustGet("service.api"))
+	assert.Equal(t, 10, ctn.MustGet("param.rps"))
+}
+
+type ctxClosableService struct{}
+
+func (c *ctxClosableService) Shutdown(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func TestContainer_ResetNotifiesListeners(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	l := &recordingListener{}
+
+	b := Builder(
+		Service("service.a", WithFn(func() any { return &ctxClosableService{} })),
+		Service("service.b", WithFn(func() any { return &randomService{} })),
+	)
+	b.WithListener(l)
+	ctn := b.MustBuild(ctx)
+	_ = ctn.MustGet("service.a")
+	_ = ctn.MustGet("service.b")
+
+	// the context given at Build() is not used to close the instance
+	cancel()
+	l.events = nil
+	assert.NoError(t, ctn.Reset("service.a", true))
+	assert.Equal(t, []string{"shutdown service.a <nil>"}, l.events)
+}
+
+type rateLimitedClient struct {
+	closableService
+	RPS int `inject:"param.rps"`
+}
+
+func TestContainer_ResetProvidedInstance(t *testing.T) {
+	closed := make([]string, 0)
+	db := &closableService{name: "db", closed: &closed}
+	client := &rateLimitedClient{closableService: closableService{name: "client", closed: &closed}}
+
+	ctn := Builder(
+		Param("param.rps", 10),
+		Service("service.db", WithInstance(db)),
+		Service("service.client", WithInstance(client)),
+	).
+		MustBuild(context.TODO())
+
+	assert.Same(t, db, ctn.MustGet("service.db"))
+	assert.Same(t, client, ctn.MustGet("service.client"))
+	assert.Equal(t, 10, client.RPS)
+
+	// instances passed in by WithInstance are not owned by the container
+	assert.NoError(t, ctn.Reset("service.db", false))
+	assert.Same(t, db, ctn.MustGet("service.db"))
+
+	// but they are injected again
+	assert.NoError(t, ctn.SetParam("param.rps", 20))
+	assert.Same(t, client, ctn.MustGet("service.client"))
+	assert.Equal(t, 20, client.RPS)
+	assert.Empty(t, closed)
+}
@@ -0,0 +1,122 @@
+package dimple
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Retry describes how often a factory is called until it succeeds
+type Retry struct {
+	// Attempts is the maximum number of calls. Values less than 2 mean no retry.
+	Attempts int
+	// Backoff is the delay before the second attempt. It doubles for each further attempt.
+	Backoff time.Duration
+}
+
+// attempt calls fn until it succeeds or the retry policy of the definition is exhausted. Each attempt is limited
+// by the timeout of the definition. The error returned contains the errors of all attempts.
+func (c *DefaultContainer) attempt(def Definition, fn func(scoped *DefaultContainer) (any, error)) (any, error) {
+	timeout, retry := getPolicy(def)
+
+	errs := make([]error, 0)
+	backoff := retry.Backoff
+	for i := 1; ; i++ {
+		instance, err := c.try(timeout, fn)
+		if err == nil {
+			return instance, nil
+		}
+
+		if ctxErr := c.callerCtx().Err(); ctxErr != nil {
+			// the factory has most likely been aborted
+			return nil, newCancelledError(def.Id(), ctxErr)
+		}
+
+		if retry.Attempts < 2 {
+			return nil, newFactoryError(def, err)
+		}
+
+		errs = append(errs, fmt.Errorf(`attempt %d: %w`, i, err))
+		if i >= retry.Attempts {
+			return nil, newFactoryError(def, joinErrors(errs))
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-c.callerCtx().Done():
+			return nil, newCancelledError(def.Id(), c.callerCtx().Err())
+		}
+
+		backoff *= 2
+	}
+}
+
+// try calls fn with a container whose context has a deadline if timeout is set
+func (c *DefaultContainer) try(timeout time.Duration, fn func(scoped *DefaultContainer) (any, error)) (any, error) {
+	if timeout <= 0 {
+		return fn(c)
+	}
+
+	parent := c.ctx
+	if c.caller == nil {
+		// the context given at Build() must not cut the factory short
+		parent = context.WithoutCancel(parent)
+	}
+
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	instance, err := fn(c.withContext(ctx))
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf(`timed out after %s: %w`, timeout, err)
+	}
+
+	return instance, err
+}
+
+// callWithDeadline calls fn and returns once fn returned or ctx is done, whichever happens first. An instance
+// returned by an abandoned call will be closed.
+func callWithDeadline(ctx context.Context, fn FactoryFnWithError) (any, error) {
+	type result struct {
+		instance any
+		err      error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- result{err: fmt.Errorf(`factory panicked: %v`, r)}
+			}
+		}()
+
+		instance, err := fn()
+		done <- result{instance: instance, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.instance, r.err
+	case <-ctx.Done():
+		go func() {
+			if r := <-done; r.instance != nil {
+				_ = closeInstance(context.Background(), r.instance)
+			}
+		}()
+
+		return nil, ctx.Err()
+	}
+}
+
+// getPolicy returns the timeout and retry policy of the given definition
+func getPolicy(def Definition) (time.Duration, Retry) {
+	switch t := def.(type) {
+	case DecoratorDef:
+		return t.Timeout(), t.Retry()
+	case ServiceDef:
+		return t.Timeout(), t.Retry()
+	default:
+		return 0, Retry{}
+	}
+}
@@ -0,0 +1,153 @@
+// nolint
+package dimple
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	ctn := Builder(
+		Service("service.a", WithErrorFn(func() (any, error) {
+			calls++
+			if calls < 3 {
+				return nil, fmt.Errorf("transient error %d", calls)
+			}
+
+			return &randomService{}, nil
+		})).WithRetry(3, time.Millisecond),
+	).
+		MustBuild(context.TODO())
+
+	_, err := ctn.Get("service.a")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryExhausted(t *testing.T) {
+	errs := []error{errors.New("error 1"), errors.New("error 2")}
+	calls := 0
+
+	ctn := Builder(
+		Service("service.a", WithErrorFn(func() (any, error) {
+			calls++
+			return nil, errs[calls-1]
+		})).WithRetry(2, time.Millisecond),
+	).
+		MustBuild(context.TODO())
+
+	_, err := ctn.Get("service.a")
+	assert.ErrorIs(t, err, ErrServiceFactoryFailed)
+	assert.ErrorIs(t, err, errs[0])
+	assert.ErrorIs(t, err, errs[1])
+	assert.ErrorContains(t, err, "attempt 1: error 1")
+	assert.ErrorContains(t, err, "attempt 2: error 2")
+	assert.Equal(t, 2, calls)
+}
+
+type abandonedService struct {
+	closed chan struct{}
+}
+
+func (a *abandonedService) Close() {
+	close(a.closed)
+}
+
+func TestTimeoutAbandonsFactory(t *testing.T) {
+	var calls atomic.Int32
+	closed := make(chan struct{})
+	ctn := Builder(
+		Service("service.a", WithErrorFn(func() (any, error) {
+			if calls.Add(1) == 1 {
+				time.Sleep(50 * time.Millisecond)
+
+				return &abandonedService{closed: closed}, nil
+			}
+
+			time.Sleep(50 * time.Millisecond)
+
+			return nil, errors.New("too late")
+		})).WithTimeout(10*time.Millisecond).WithRetry(2, 0),
+		Service("service.b", WithErrorFn(func() (any, error) {
+			return &randomService{Name: "B"}, nil
+		})).WithTimeout(10*time.Millisecond),
+	).
+		MustBuild(context.TODO())
+
+	start := time.Now()
+	_, err := ctn.Get("service.a")
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+	assert.ErrorIs(t, err, ErrServiceFactoryFailed)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "attempt 1: timed out after 10ms")
+	assert.ErrorContains(t, err, "attempt 2: timed out after 10ms")
+
+	// the instance of the abandoned call gets closed
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Errorf("abandoned instance has not been closed")
+	}
+
+	b, err := ctn.Get("service.b")
+	assert.NoError(t, err)
+	assert.Equal(t, "B", b.(*randomService).Name)
+}
+
+func TestTimeout(t *testing.T) {
+	calls := 0
+	ctn := Builder(
+		Service("service.a", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			calls++
+			if calls == 1 {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+
+			deadline, ok := ctx.Deadline()
+			assert.True(t, ok)
+			assert.WithinDuration(t, time.Now().Add(10*time.Millisecond), deadline, 10*time.Millisecond)
+
+			return &randomService{}, nil
+		})).WithTimeout(10*time.Millisecond).WithRetry(2, 0),
+		Service("service.b", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})).WithTimeout(10*time.Millisecond).WithRetry(2, 0),
+	).
+		MustBuild(context.TODO())
+
+	_, err := ctn.Get("service.b")
+	assert.ErrorIs(t, err, ErrServiceFactoryFailed)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotErrorIs(t, err, ErrResolutionCancelled)
+	assert.ErrorContains(t, err, "attempt 2: timed out after 10ms")
+
+	_, err = ctn.Get("service.a")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestRetryCancelled(t *testing.T) {
+	ctn := Builder(
+		Decorator("decorator.a", "service.a", WithErrorFn(func() (any, error) {
+			return nil, errors.New("transient error")
+		})).WithRetry(3, time.Second),
+		Service("service.a", WithInstance(&randomService{})),
+	).
+		MustBuild(context.TODO())
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := ctn.GetCtx(ctx, "service.a")
+	assert.ErrorIs(t, err, ErrResolutionCancelled)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
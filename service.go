@@ -1,21 +1,35 @@
 package dimple
 
+import "time"
+
 var _ ServiceDef = (*serviceDef)(nil)
 
 // Service returns a new instance of  ServiceDef
 func Service(id string, factory Factory) ServiceDef {
 	return &serviceDef{
 		definition: definition{
-			id: id,
+			id:     id,
+			source: captureSource(2),
 		},
 		factory: factory,
 	}
 }
 
+// Call describes a method which will be called on the service instance once it has been instantiated.
+// Each argument is the ID of a service or param whose value will be passed to the method.
+type Call struct {
+	Method string
+	Args   []string
+}
+
 type serviceDef struct {
 	definition
 	factory  Factory
 	instance any
+	calls    []Call
+	tags     []Tag
+	timeout  time.Duration
+	retry    Retry
 }
 
 func (s *serviceDef) clone() *serviceDef {
@@ -23,9 +37,27 @@ func (s *serviceDef) clone() *serviceDef {
 		definition: *s.definition.clone(),
 		factory:    s.Factory(),
 		instance:   s.Instance(),
+		calls:      s.Calls(),
+		tags:       s.Tags(),
+		timeout:    s.Timeout(),
+		retry:      s.Retry(),
 	}
 }
 
+func (s *serviceDef) WithNamespace(namespace string) ServiceDef {
+	c := s.clone()
+	c.namespace = namespace
+
+	return c
+}
+
+func (s *serviceDef) WithPrivate() ServiceDef {
+	c := s.clone()
+	c.private = true
+
+	return c
+}
+
 func (s *serviceDef) WithID(id string) ServiceDef {
 	c := s.clone()
 	c.id = id
@@ -47,6 +79,52 @@ func (s *serviceDef) WithInstance(instance any) ServiceDef {
 	return c
 }
 
+func (s *serviceDef) WithCall(method string, args ...string) ServiceDef {
+	c := s.clone()
+	c.calls = append(make([]Call, 0, len(s.calls)+1), s.calls...)
+	c.calls = append(c.calls, Call{Method: method, Args: args})
+
+	return c
+}
+
+func (s *serviceDef) WithTag(name string, attributes ...string) ServiceDef {
+	c := s.clone()
+	c.tags = append(make([]Tag, 0, len(s.tags)+1), s.tags...)
+	c.tags = append(c.tags, newTag(name, attributes...))
+
+	return c
+}
+
+func (s *serviceDef) WithTimeout(timeout time.Duration) ServiceDef {
+	c := s.clone()
+	c.timeout = timeout
+
+	return c
+}
+
+func (s *serviceDef) WithRetry(attempts int, backoff time.Duration) ServiceDef {
+	c := s.clone()
+	c.retry = Retry{Attempts: attempts, Backoff: backoff}
+
+	return c
+}
+
+func (s *serviceDef) Timeout() time.Duration {
+	return s.timeout
+}
+
+func (s *serviceDef) Retry() Retry {
+	return s.retry
+}
+
+func (s *serviceDef) Tags() []Tag {
+	return s.tags
+}
+
+func (s *serviceDef) Calls() []Call {
+	return s.calls
+}
+
 func (s *serviceDef) Instance() any {
 	return s.instance
 }
@@ -156,3 +156,69 @@ func TestServiceWithContext(t *testing.T) {
 	assert.Nil(t, a.B)
 	assert.Nil(t, a.C)
 }
+
+type setterService struct {
+	a    *randomService
+	name string
+}
+
+func (s *setterService) SetA(a *randomService) {
+	s.a = a
+}
+
+func (s *setterService) SetName(name string) error {
+	if name == "" {
+		return fmt.Errorf("name must not be empty")
+	}
+
+	s.name = name
+
+	return nil
+}
+
+func TestServiceWithCall(t *testing.T) {
+	const serviceA = "service.a"
+	const serviceB = "service.b"
+	const paramName = "param.name"
+
+	container := Builder(
+		Param(paramName, "B"),
+		Service(serviceA, WithFn(func() any {
+			return &randomService{Name: "A"}
+		})),
+		Service(serviceB, WithFn(func() any {
+			return &setterService{}
+		})).
+			WithCall("SetA", serviceA).
+			WithCall("SetName", paramName),
+	).MustBuild(context.TODO())
+
+	b := container.MustGet(serviceB).(*setterService)
+	assert.Same(t, container.MustGet(serviceA), b.a)
+	assert.Equal(t, "B", b.name)
+}
+
+func TestServiceWithCallErrors(t *testing.T) {
+	const serviceA = "service.a"
+	const paramName = "param.name"
+
+	for _, call := range []Call{
+		{Method: "Unknown"},
+		{Method: "SetA"},
+		{Method: "SetA", Args: []string{paramName}},
+		{Method: "SetName", Args: []string{"unknown"}},
+		{Method: "SetName", Args: []string{"empty"}},
+	} {
+		container := Builder(
+			Param(paramName, "A"),
+			Param("empty", ""),
+			Service(serviceA, WithFn(func() any {
+				return &setterService{}
+			})).WithCall(call.Method, call.Args...),
+		).MustBuild(context.TODO())
+
+		_, err := container.Get(serviceA)
+		assert.Error(t, err, call.Method)
+		assert.Contains(t, err.Error(), call.Method)
+	}
+}
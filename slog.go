@@ -0,0 +1,141 @@
+package dimple
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var _ Listener = (*SlogListener)(nil)
+
+// SlogListener is a Listener logging what the container does to a slog.Logger. Registrations, instantiations,
+// decorations and injections are logged at debug level, boot and shutdown summaries at info level and failures
+// at error level.
+type SlogListener struct {
+	logger *slog.Logger
+	kinds  sync.Map
+}
+
+// NewSlogListener returns a new SlogListener logging to the given logger
+func NewSlogListener(logger *slog.Logger) *SlogListener {
+	return &SlogListener{
+		logger: logger,
+	}
+}
+
+// WithLogger registers a SlogListener logging to the given logger
+func (b *DefaultBuilder) WithLogger(logger *slog.Logger) *DefaultBuilder {
+	return b.WithListener(NewSlogListener(logger))
+}
+
+// definitionKind describes the kind of definition and its factory
+type definitionKind struct {
+	kind    string
+	factory string
+}
+
+func (s *SlogListener) OnDefinitionAdded(def Definition) {
+	k := definitionKind{kind: kindOf(def)}
+	switch t := def.(type) {
+	case DecoratorDef:
+		k.factory = factoryKind(t.Factory())
+	case ServiceDef:
+		k.factory = factoryKind(t.Factory())
+	}
+
+	s.kinds.Store(def.Id(), k)
+	s.logger.Debug("definition added",
+		slog.String("id", def.Id()),
+		slog.String("kind", k.kind),
+		slog.String("namespace", def.Namespace()),
+		slog.Bool("private", def.Private()),
+		slog.String("source", def.Source()),
+	)
+}
+
+func (s *SlogListener) BeforeInstantiate(string) {}
+
+func (s *SlogListener) AfterInstantiate(id string, _ any, duration time.Duration, err error) {
+	k, _ := s.kinds.Load(id)
+	kind, _ := k.(definitionKind)
+
+	attrs := []slog.Attr{
+		slog.String("id", id),
+		slog.String("kind", kind.kind),
+		slog.String("factory", kind.factory),
+		slog.Duration("duration", duration),
+	}
+
+	if err != nil {
+		s.logger.LogAttrs(context.Background(), slog.LevelError, "instantiation failed", append(attrs, slog.Any("error", err))...)
+		return
+	}
+
+	s.logger.LogAttrs(context.Background(), slog.LevelDebug, "instantiated", attrs...)
+}
+
+func (s *SlogListener) OnRewire(id string, decorators []string) {
+	s.logger.Debug("decorators wired", slog.String("id", id), slog.Any("decorators", decorators))
+}
+
+func (s *SlogListener) OnDecorate(id string, decorator string) {
+	s.logger.Debug("decorated", slog.String("id", id), slog.String("decorator", decorator))
+}
+
+func (s *SlogListener) OnInject(target any, field string, id string) {
+	s.logger.Debug("injected", slog.String("target", fmt.Sprintf("%T", target)), slog.String("field", field), slog.String("id", id))
+}
+
+func (s *SlogListener) OnInjectFailed(target any, field string, id string, err error) {
+	s.logger.Error("injection failed",
+		slog.String("target", fmt.Sprintf("%T", target)),
+		slog.String("field", field),
+		slog.String("id", id),
+		slog.Any("error", err),
+	)
+}
+
+func (s *SlogListener) AfterBoot(services int, duration time.Duration, err error) {
+	if err != nil {
+		s.logger.Error("boot failed", slog.Int("services", services), slog.Duration("duration", duration), slog.Any("error", err))
+		return
+	}
+
+	s.logger.Info("booted", slog.Int("services", services), slog.Duration("duration", duration))
+}
+
+func (s *SlogListener) OnShutdown(id string, err error) {
+	if err != nil {
+		s.logger.Error("shutdown failed", slog.String("id", id), slog.Any("error", err))
+		return
+	}
+
+	s.logger.Debug("shut down", slog.String("id", id))
+}
+
+func (s *SlogListener) AfterShutdown(services int, duration time.Duration, err error) {
+	if err != nil {
+		s.logger.Error("shutdown incomplete", slog.Int("services", services), slog.Duration("duration", duration), slog.Any("error", err))
+		return
+	}
+
+	s.logger.Info("shut down all services", slog.Int("services", services), slog.Duration("duration", duration))
+}
+
+// factoryKind describes which kind of factory function is used
+func factoryKind(f Factory) string {
+	switch {
+	case f == nil:
+		return ""
+	case f.Instance() != nil:
+		return "instance"
+	case f.FactoryFnWithContext() != nil:
+		return "context"
+	case f.FactoryFnWithError() != nil:
+		return "error"
+	default:
+		return "fn"
+	}
+}
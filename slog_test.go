@@ -0,0 +1,72 @@
+// nolint
+package dimple
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type slogInjectable struct {
+	Missing string `inject:"param.missing"`
+}
+
+func TestSlogListener(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			// strip the attributes which vary on each run
+			if a.Key == slog.TimeKey || a.Key == "duration" || a.Key == "source" {
+				return slog.Attr{}
+			}
+
+			return a
+		},
+	}))
+
+	ctn := Builder(
+		Service("service.a", WithFn(func() any { return &randomService{} })),
+		Decorator("decorator.a", "service.a", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return ctx.Decorated(), nil
+		})),
+		Service("service.b", WithErrorFn(func() (any, error) { return nil, errors.New("boom") })),
+	).
+		WithLogger(logger).
+		MustBuild(context.TODO())
+
+	assert.Error(t, ctn.Boot())
+	assert.Error(t, ctn.Inject(&slogInjectable{}))
+	assert.NoError(t, ctn.Shutdown(context.TODO()))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for _, expected := range []string{
+		`level=DEBUG msg="definition added" id=service.a kind=service namespace="" private=false`,
+		`level=DEBUG msg="decorators wired" id=service.a decorators=[decorator.a]`,
+		`level=DEBUG msg=instantiated id=service.a kind=service factory=fn`,
+		`level=DEBUG msg=instantiated id=decorator.a kind=decorator factory=context`,
+		`level=DEBUG msg=decorated id=service.a decorator=decorator.a`,
+		`level=ERROR msg="instantiation failed" id=service.b kind=service factory=error`,
+		`level=ERROR msg="boot failed"`,
+		`level=ERROR msg="injection failed" target=*dimple.slogInjectable field=Missing id=param.missing`,
+		`level=DEBUG msg="shut down" id=service.a`,
+		`level=INFO msg="shut down all services" services=1`,
+	} {
+		assert.True(t, containsPrefix(lines, expected), "missing log line %s in\n%s", expected, buf.String())
+	}
+}
+
+func containsPrefix(lines []string, prefix string) bool {
+	for _, line := range lines {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
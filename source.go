@@ -0,0 +1,71 @@
+package dimple
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// captureSource returns the program counter of the caller, skip is relative to the caller of captureSource().
+// It is resolved to file and line by sourceOf() only when needed, since that is the expensive part.
+func captureSource(skip int) uintptr {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+1, pcs[:]) == 0 {
+		return 0
+	}
+
+	return pcs[0]
+}
+
+// sourceOf returns the file and line of the program counter captured by captureSource()
+func sourceOf(pc uintptr) string {
+	if pc == 0 {
+		return ""
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if frame.File == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(`%s:%d`, frame.File, frame.Line)
+}
+
+// withoutSource returns a copy of the definition without its source
+func withoutSource(def Definition) Definition {
+	switch t := def.(type) {
+	case *paramDef:
+		c := t.clone()
+		c.source = 0
+
+		return c
+	case *serviceDef:
+		c := t.clone()
+		c.source = 0
+
+		return c
+	case *decoratorDef:
+		c := t.clone()
+		c.source = 0
+
+		return c
+	default:
+		return def
+	}
+}
+
+func formatSource(source string) string {
+	if source == "" {
+		return "<unknown>"
+	}
+
+	return source
+}
+
+// declaredAt returns a hint where the definition has been declared if known
+func declaredAt(def Definition) string {
+	if def == nil || def.Source() == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(` declared at %s`, def.Source())
+}
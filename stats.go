@@ -0,0 +1,238 @@
+package dimple
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/thoas/go-funk"
+)
+
+// ServiceStats describes the instantiation of a service or decorator
+type ServiceStats struct {
+	ID           string
+	Kind         string
+	Instantiated bool
+	// Eager is TRUE if the service has been instantiated by Boot() or BootParallel() rather than on first use
+	Eager bool
+	// Resolutions is the number of times the service has been requested including requests by other services
+	Resolutions int64
+	// Duration is the time the instantiation took including the instantiation of its dependencies
+	Duration time.Duration
+	// SelfDuration is the time the instantiation took excluding the instantiation of its dependencies
+	SelfDuration time.Duration
+}
+
+// Stats describes the instantiation of all services
+type Stats struct {
+	// Services lists all services and decorators in order of registration
+	Services []ServiceStats
+	// CriticalPath is the chain of instantiations starting at the slowest one which has not been triggered by another,
+	// followed by the slowest instantiation each of them triggered
+	CriticalPath []string
+	// CriticalPathDuration is the time the instantiation of the critical path took
+	CriticalPathDuration time.Duration
+}
+
+// serviceStats collects the stats of a single service. Except for resolutions all fields are guarded by the lock
+// of the container.
+type serviceStats struct {
+	resolutions  atomic.Int64
+	instantiated bool
+	eager        bool
+	duration     time.Duration
+	nested       time.Duration
+	children     []string
+	parent       string
+}
+
+// Stats returns the instantiation time, resolutions and the critical path of the instantiated services
+func (c *DefaultContainer) Stats() Stats {
+	root := c.getRoot()
+	stats := Stats{
+		Services: make([]ServiceStats, 0),
+	}
+
+	ids := root.getOrder()
+	kinds := make(map[string]string, len(ids))
+	for _, id := range ids {
+		kinds[id] = kindOf(root.lookup(id))
+	}
+
+	root.Lock()
+	defer root.Unlock()
+
+	for _, id := range ids {
+		if kinds[id] != "service" && kinds[id] != "decorator" {
+			continue
+		}
+
+		info := ServiceStats{ID: id, Kind: kinds[id]}
+		if s, ok := root.stats[id]; ok {
+			info.Instantiated = s.instantiated
+			info.Eager = s.eager
+			info.Resolutions = s.resolutions.Load()
+			info.Duration = s.duration
+			info.SelfDuration = s.duration - s.nested
+		}
+
+		stats.Services = append(stats.Services, info)
+	}
+
+	// the critical path starts at the slowest instantiation which has not been triggered by another one
+	var next string
+	for id, s := range root.stats {
+		if s.parent == "" && s.instantiated && (next == "" || s.duration > root.stats[next].duration) {
+			next = id
+		}
+	}
+
+	if next != "" {
+		stats.CriticalPathDuration = root.stats[next].duration
+	}
+
+	for next != "" && !funk.ContainsString(stats.CriticalPath, next) {
+		stats.CriticalPath = append(stats.CriticalPath, next)
+
+		slowest := ""
+		for _, child := range root.stats[next].children {
+			if slowest == "" || root.stats[child].duration > root.stats[slowest].duration {
+				slowest = child
+			}
+		}
+
+		next = slowest
+	}
+
+	return stats
+}
+
+// StartupReport writes the instantiated services ordered by their duration to w. Services whose own
+// instantiation took longer than the threshold are highlighted.
+func (c *DefaultContainer) StartupReport(w io.Writer, threshold time.Duration) error {
+	stats := c.Stats()
+
+	services := make([]ServiceStats, 0, len(stats.Services))
+	for _, s := range stats.Services {
+		if s.Instantiated {
+			services = append(services, s)
+		}
+	}
+
+	sort.SliceStable(services, func(i, j int) bool {
+		return services[i].Duration > services[j].Duration
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "\tID\tKIND\tDURATION\tSELF\tRESOLUTIONS\tBOOT"); err != nil {
+		return err
+	}
+
+	slow := 0
+	for _, s := range services {
+		marker := ""
+		if s.SelfDuration > threshold {
+			marker = "SLOW"
+			slow++
+		}
+
+		boot := "lazy"
+		if s.Eager {
+			boot = "eager"
+		}
+
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n", marker, s.ID, s.Kind, s.Duration.Round(time.Microsecond), s.SelfDuration.Round(time.Microsecond), s.Resolutions, boot); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\n%d services instantiated, %d slower than %s\ncritical path (%s): %s\n",
+		len(services), slow, threshold, stats.CriticalPathDuration.Round(time.Microsecond), strings.Join(stats.CriticalPath, " -> "))
+
+	return err
+}
+
+// measure starts measuring the instantiation of the service by given id. The returned function stops it.
+func (c *DefaultContainer) measure(id string) func(err error) {
+	root := c.getRoot()
+	eager := root.booting.Load() > 0
+	start := time.Now()
+
+	parent := ""
+	if c.ref != nil {
+		parent = *c.ref
+	}
+
+	return func(err error) {
+		duration := time.Since(start)
+
+		root.Lock()
+		defer root.Unlock()
+
+		s := root.getStats(id)
+		s.instantiated = err == nil
+		s.eager = eager
+		s.duration = duration
+		s.parent = parent
+
+		if parent != "" {
+			p := root.getStats(parent)
+			p.nested += duration
+			if !funk.ContainsString(p.children, id) {
+				p.children = append(p.children, id)
+			}
+		}
+	}
+}
+
+// resetStats forgets the instantiation of the services by given IDs, so they will be measured again on next use.
+// The resolutions are kept.
+func (c *DefaultContainer) resetStats(ids ...string) {
+	c.Lock()
+	defer c.Unlock()
+
+	for _, id := range ids {
+		if s, ok := c.stats[id]; ok {
+			s.instantiated = false
+			s.eager = false
+			s.duration = 0
+			s.nested = 0
+			s.children = nil
+			s.parent = ""
+		}
+	}
+}
+
+// countResolution counts a request of the service by given id
+func (c *DefaultContainer) countResolution(id string) {
+	root := c.getRoot()
+
+	root.Lock()
+	s := root.getStats(id)
+	root.Unlock()
+
+	s.resolutions.Add(1)
+}
+
+// getStats returns the stats of the service by given id. The caller has to hold the lock.
+func (c *DefaultContainer) getStats(id string) *serviceStats {
+	if c.stats == nil {
+		c.stats = make(map[string]*serviceStats)
+	}
+
+	s, ok := c.stats[id]
+	if !ok {
+		s = &serviceStats{}
+		c.stats[id] = s
+	}
+
+	return s
+}
@@ -0,0 +1,191 @@
+// nolint
+package dimple
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getServiceStats(stats Stats, id string) ServiceStats {
+	for _, s := range stats.Services {
+		if s.ID == id {
+			return s
+		}
+	}
+
+	return ServiceStats{}
+}
+
+func TestContainer_Stats(t *testing.T) {
+	ctn := Builder(
+		Service("app", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			_ = ctx.Container().MustGet("cache")
+			return ctx.Container().Get("repository")
+		})),
+		Service("repository", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return ctx.Container().Get("db")
+		})),
+		Service("db", WithFn(func() any {
+			time.Sleep(20 * time.Millisecond)
+			return &randomService{Name: "db"}
+		})),
+		Service("cache", WithFn(func() any {
+			return &randomService{Name: "cache"}
+		})),
+		Param("param", "value"),
+	).
+		MustBuild(context.TODO())
+
+	assert.NoError(t, ctn.Boot())
+	_ = ctn.MustGet("app")
+	_ = ctn.MustGet("app")
+
+	stats := ctn.Stats()
+	assert.Equal(t, []string{"app", "repository", "db"}, stats.CriticalPath)
+	assert.GreaterOrEqual(t, stats.CriticalPathDuration, 20*time.Millisecond)
+
+	app := getServiceStats(stats, "app")
+	assert.True(t, app.Instantiated)
+	assert.True(t, app.Eager)
+	assert.Equal(t, int64(3), app.Resolutions)
+	assert.GreaterOrEqual(t, app.Duration, 20*time.Millisecond)
+	assert.Less(t, app.SelfDuration, 20*time.Millisecond)
+
+	db := getServiceStats(stats, "db")
+	assert.Equal(t, int64(2), db.Resolutions)
+	assert.GreaterOrEqual(t, db.SelfDuration, 20*time.Millisecond)
+
+	// params are not listed
+	assert.Equal(t, ServiceStats{}, getServiceStats(stats, "param"))
+}
+
+func TestContainer_StatsLazy(t *testing.T) {
+	ctn := Builder(
+		Service("app", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			_ = ctx.Container().MustGet("cache")
+			return ctx.Container().Get("repository")
+		})),
+		Service("repository", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return ctx.Container().Get("db")
+		})),
+		Service("db", WithFn(func() any {
+			time.Sleep(20 * time.Millisecond)
+			return &randomService{Name: "db"}
+		})),
+		Service("cache", WithFn(func() any {
+			return &randomService{Name: "cache"}
+		})),
+		Param("param", "value"),
+	).
+		MustBuild(context.TODO())
+
+	_ = ctn.MustGet("repository")
+
+	stats := ctn.Stats()
+	assert.Equal(t, []string{"repository", "db"}, stats.CriticalPath)
+	assert.False(t, getServiceStats(stats, "repository").Eager)
+	assert.False(t, getServiceStats(stats, "app").Instantiated)
+	assert.Equal(t, int64(0), getServiceStats(stats, "app").Resolutions)
+}
+
+func TestContainer_StatsCompiled(t *testing.T) {
+	ctn := Builder(
+		Service("app", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			_ = ctx.Container().MustGet("cache")
+			return ctx.Container().Get("repository")
+		})),
+		Service("repository", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return ctx.Container().Get("db")
+		})),
+		Service("db", WithFn(func() any {
+			time.Sleep(20 * time.Millisecond)
+			return &randomService{Name: "db"}
+		})),
+		Service("cache", WithFn(func() any {
+			return &randomService{Name: "cache"}
+		})),
+		Param("param", "value"),
+	).
+		MustBuild(context.TODO())
+
+	assert.NoError(t, ctn.Compile())
+	_ = ctn.MustGet("cache")
+	_ = ctn.MustGet("cache")
+
+	assert.Equal(t, int64(2), getServiceStats(ctn.Stats(), "cache").Resolutions)
+}
+
+func TestContainer_StatsReset(t *testing.T) {
+	ctn := Builder(
+		Service("app", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			_ = ctx.Container().MustGet("cache")
+			return ctx.Container().Get("repository")
+		})),
+		Service("repository", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return ctx.Container().Get("db")
+		})),
+		Service("db", WithFn(func() any {
+			time.Sleep(20 * time.Millisecond)
+			return &randomService{Name: "db"}
+		})),
+		Service("cache", WithFn(func() any {
+			return &randomService{Name: "cache"}
+		})),
+		Param("param", "value"),
+	).
+		MustBuild(context.TODO())
+
+	assert.NoError(t, ctn.Boot())
+	assert.Equal(t, []string{"app", "repository", "db"}, ctn.Stats().CriticalPath)
+
+	assert.NoError(t, ctn.Reset("cache", true))
+	stats := ctn.Stats()
+	assert.NotContains(t, stats.CriticalPath, "app")
+	assert.False(t, getServiceStats(stats, "app").Instantiated)
+	assert.False(t, getServiceStats(stats, "cache").Instantiated)
+	assert.True(t, getServiceStats(stats, "db").Instantiated)
+
+	// the services instantiated again are measured from scratch
+	_ = ctn.MustGet("app")
+	stats = ctn.Stats()
+	assert.True(t, getServiceStats(stats, "app").Instantiated)
+	assert.False(t, getServiceStats(stats, "app").Eager)
+	assert.Less(t, getServiceStats(stats, "app").Duration, 20*time.Millisecond)
+	assert.Equal(t, []string{"cache"}, ctn.stats["app"].children)
+}
+
+func TestContainer_StartupReport(t *testing.T) {
+	ctn := Builder(
+		Service("app", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			_ = ctx.Container().MustGet("cache")
+			return ctx.Container().Get("repository")
+		})),
+		Service("repository", WithContextFn(func(ctx FactoryCtx) (any, error) {
+			return ctx.Container().Get("db")
+		})),
+		Service("db", WithFn(func() any {
+			time.Sleep(20 * time.Millisecond)
+			return &randomService{Name: "db"}
+		})),
+		Service("cache", WithFn(func() any {
+			return &randomService{Name: "cache"}
+		})),
+		Param("param", "value"),
+	).
+		MustBuild(context.TODO())
+
+	assert.NoError(t, ctn.Boot())
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, ctn.StartupReport(buf, 10*time.Millisecond))
+
+	report := buf.String()
+	assert.Regexp(t, `SLOW\s+db\s+service\s+\S+\s+\S+\s+2\s+eager`, report)
+	assert.Regexp(t, `\n\s+cache\s+service`, report)
+	assert.Contains(t, report, "6 services instantiated, 1 slower than 10ms")
+	assert.Contains(t, report, "app -> repository -> db\n")
+}
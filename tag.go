@@ -0,0 +1,27 @@
+package dimple
+
+// Tag can be attached to a service to group it with others. The attributes allow to add arbitrary
+// key-value information e.g. the path of a http route.
+type Tag struct {
+	Name       string
+	Attributes map[string]string
+}
+
+// newTag returns a new Tag. The attributes are expected as alternating keys and values.
+func newTag(name string, attributes ...string) Tag {
+	tag := Tag{
+		Name:       name,
+		Attributes: make(map[string]string),
+	}
+
+	for i := 0; i+1 < len(attributes); i += 2 {
+		tag.Attributes[attributes[i]] = attributes[i+1]
+	}
+
+	return tag
+}
+
+// Attribute returns the value of the attribute by given key or an empty string if it does not exist
+func (t Tag) Attribute(key string) string {
+	return t.Attributes[key]
+}
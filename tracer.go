@@ -0,0 +1,81 @@
+package dimple
+
+import (
+	"context"
+	"fmt"
+)
+
+// Tracer starts spans for the instantiation of services. Implement it to adapt OpenTelemetry or any other
+// tracing library. The span of a service is the parent of the spans of all services it depends on.
+type Tracer interface {
+	// Start starts a new span which is a child of the span in ctx if any. The returned context carries the new span.
+	Start(ctx context.Context, name string, attributes map[string]string) (context.Context, Span)
+}
+
+// Span represents a single instantiation traced by a Tracer
+type Span interface {
+	// SetError records the error the instantiation failed with
+	SetError(err error)
+	// End completes the span
+	End()
+}
+
+const (
+	// TraceAttrServiceID is the span attribute holding the ID of the service
+	TraceAttrServiceID = "dimple.service.id"
+	// TraceAttrKind is the span attribute holding the kind of definition, either "service" or "decorator"
+	TraceAttrKind = "dimple.definition.kind"
+)
+
+// WithTracer registers a tracer which starts a span for each instantiation of a service or decorator
+func (b *DefaultBuilder) WithTracer(t Tracer) *DefaultBuilder {
+	c := b.container
+	c.Lock()
+	c.tracer = t
+	c.Unlock()
+
+	return b
+}
+
+// getTracedIndirect returns an indirection like getIndirect() whose context carries a span for the instantiation
+// of the definition by given id. The returned function ends the span.
+func (c *DefaultContainer) getTracedIndirect(id string, def Definition) (*DefaultContainer, func(err error)) {
+	indirection := c.getIndirect(id)
+
+	root := c.getRoot()
+	root.Lock()
+	tracer := root.tracer
+	root.Unlock()
+
+	if tracer == nil {
+		return indirection, func(error) {}
+	}
+
+	ctx, span := tracer.Start(c.ctx, fmt.Sprintf(`instantiate %s`, id), map[string]string{
+		TraceAttrServiceID: id,
+		TraceAttrKind:      kindOf(def),
+	})
+	indirection.ctx = ctx
+
+	return indirection, func(err error) {
+		if err != nil {
+			span.SetError(err)
+		}
+
+		span.End()
+	}
+}
+
+// kindOf returns the kind of the given definition
+func kindOf(def Definition) string {
+	switch def.(type) {
+	case ParamDef:
+		return "param"
+	case DecoratorDef:
+		return "decorator"
+	case ServiceDef:
+		return "service"
+	default:
+		return ""
+	}
+}